@@ -0,0 +1,82 @@
+package archrules
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Pattern matches dot-separated namespace and leaf ids segment by segment. A
+// "*" segment matches exactly one segment and a "**" segment any number of
+// segments including none, so "domain.**" matches domain itself and
+// everything below it. Inside a segment, "*" matches any run of characters,
+// e.g. "*Service". Patterns are anchored at both ends.
+type Pattern struct {
+	text     string
+	segments []string
+}
+
+// ParsePattern parses a pattern such as "domain.**".
+func ParsePattern(text string) (Pattern, error) {
+	if text == "" {
+		return Pattern{}, fmt.Errorf("empty pattern")
+	}
+	segments := strings.Split(text, ".")
+	for _, segment := range segments {
+		if segment == "" {
+			return Pattern{}, fmt.Errorf("pattern %q contains an empty segment", text)
+		}
+		if strings.Contains(segment, "**") && segment != "**" {
+			return Pattern{}, fmt.Errorf("pattern %q: ** must be a segment of its own", text)
+		}
+	}
+	return Pattern{text: text, segments: segments}, nil
+}
+
+// Matches reports whether id matches the pattern.
+func (p Pattern) Matches(id string) bool {
+	return matchSegments(p.segments, strings.Split(id, "."))
+}
+
+func (p Pattern) String() string {
+	return p.text
+}
+
+func matchSegments(pattern, id []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			for skipped := 0; skipped <= len(id); skipped++ {
+				if matchSegments(pattern[1:], id[skipped:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(id) == 0 || !matchSegment(pattern[0], id[0]) {
+			return false
+		}
+		pattern, id = pattern[1:], id[1:]
+	}
+	return len(id) == 0
+}
+
+// matchSegment matches a single segment in which "*" stands for any run of
+// characters.
+func matchSegment(pattern, segment string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == segment
+	}
+	if !strings.HasPrefix(segment, parts[0]) {
+		return false
+	}
+	segment = segment[len(parts[0]):]
+	last := parts[len(parts)-1]
+	for _, part := range parts[1 : len(parts)-1] {
+		index := strings.Index(segment, part)
+		if index == -1 {
+			return false
+		}
+		segment = segment[index+len(part):]
+	}
+	return len(segment) >= len(last) && strings.HasSuffix(segment, last)
+}
@@ -0,0 +1,44 @@
+package archrules
+
+import "testing"
+
+func TestPatternMatches(t *testing.T) {
+	cases := []struct {
+		pattern string
+		id      string
+		matches bool
+	}{
+		{"domain.**", "domain", true},
+		{"domain.**", "domain.model.Creature", true},
+		{"domain.**", "domainx.Creature", false},
+		{"domain.**", "application.domain.Creature", false},
+		{"domain.*", "domain.Creature", true},
+		{"domain.*", "domain.model.Creature", false},
+		{"**.model.*", "domain.model.Creature", true},
+		{"**.model.*", "model.Creature", true},
+		{"domain.**.Creature", "domain.Creature", true},
+		{"domain.**.Creature", "domain.model.Creature", true},
+		{"**.*Service", "domain.service.CreatureService", true},
+		{"**.*Service", "domain.service.ServiceLocator", false},
+		{"*.Cre*re", "model.Creature", true},
+		{"*.Cre*re", "model.Cre", false},
+		{"**", "anything.at.all", true},
+	}
+	for _, c := range cases {
+		pattern, err := ParsePattern(c.pattern)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if actual := pattern.Matches(c.id); actual != c.matches {
+			t.Errorf("%q matching %q: got %t, want %t", c.pattern, c.id, actual, c.matches)
+		}
+	}
+}
+
+func TestParsePatternRejectsMalformedPatterns(t *testing.T) {
+	for _, text := range []string{"", "domain..model", "domain.", "domain.**x"} {
+		if _, err := ParsePattern(text); err == nil {
+			t.Errorf("%q: expected an error", text)
+		}
+	}
+}
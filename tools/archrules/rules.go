@@ -0,0 +1,228 @@
+// Package archrules checks the dependencies of a .cg.json analysis against
+// team-owned architecture rules such as "domain.** must not depend on
+// adapter.**".
+//
+// A rules file contains one rule per line. Empty lines and lines starting with
+// "#" are ignored. The supported forms are
+//
+//	prefix <namespace>
+//	<source> must not depend on <target>[, <target>...]
+//	<source> may only depend on <target>[, <target>...]
+//	<source> may depend on <target>[, <target>...]
+//
+// where source and target are patterns as described at Pattern. "must not"
+// forbids the listed targets, "may only" forbids everything except the listed
+// targets, and "may depend on" declares exceptions that take precedence over
+// both. The optional prefix is stripped from leaf ids before matching, so the
+// rules can be written relative to e.g. "src.de.sots.cellarsandcentaurs";
+// dependencies of leaves outside the prefix are not checked.
+package archrules
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+)
+
+// Kind is the kind of a rule.
+type Kind string
+
+const (
+	Forbidden Kind = "must not depend on"
+	Exclusive Kind = "may only depend on"
+	Allowed   Kind = "may depend on"
+)
+
+var kinds = []Kind{Forbidden, Exclusive, Allowed}
+
+// Rule is a single line of a rules file.
+type Rule struct {
+	Kind    Kind
+	Source  Pattern
+	Targets []Pattern
+	// File and Line locate the rule for error messages.
+	File string
+	Line int
+}
+
+func (r Rule) String() string {
+	targets := make([]string, len(r.Targets))
+	for i, target := range r.Targets {
+		targets[i] = target.String()
+	}
+	return r.Source.String() + " " + string(r.Kind) + " " + strings.Join(targets, ", ")
+}
+
+// Location returns "file:line" of the rule.
+func (r Rule) Location() string {
+	return fmt.Sprintf("%s:%d", r.File, r.Line)
+}
+
+// RuleSet is a parsed rules file.
+type RuleSet struct {
+	Prefix string
+	Rules  []Rule
+}
+
+// Violation is a dependency that breaks a rule.
+type Violation struct {
+	cgjson.Edge
+	Rule Rule
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s -> %s [%s] violates %q (%s)", v.Source, v.Target, v.EdgeType(), v.Rule, v.Rule.Location())
+}
+
+// ReadFile parses the rules file at path.
+func ReadFile(path string) (*RuleSet, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	return Parse(file, path)
+}
+
+// Parse parses a rules file. name is used in error messages and rule
+// locations.
+func Parse(r io.Reader, name string) (*RuleSet, error) {
+	rules := &RuleSet{}
+	scanner := bufio.NewScanner(r)
+	for number := 1; scanner.Scan(); number++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		if prefix, ok := strings.CutPrefix(line, "prefix "); ok {
+			if rules.Prefix != "" {
+				return nil, fmt.Errorf("%s:%d: prefix is already set to %q", name, number, rules.Prefix)
+			}
+			rules.Prefix = strings.TrimSpace(prefix)
+			continue
+		}
+		rule, err := parseRule(line)
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", name, number, err)
+		}
+		rule.File = name
+		rule.Line = number
+		rules.Rules = append(rules.Rules, rule)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return rules, nil
+}
+
+func parseRule(line string) (Rule, error) {
+	for _, kind := range kinds {
+		source, targets, found := strings.Cut(line, " "+string(kind)+" ")
+		if !found {
+			continue
+		}
+		rule := Rule{Kind: kind}
+		var err error
+		if rule.Source, err = ParsePattern(strings.TrimSpace(source)); err != nil {
+			return Rule{}, err
+		}
+		for _, target := range strings.Split(targets, ",") {
+			pattern, err := ParsePattern(strings.TrimSpace(target))
+			if err != nil {
+				return Rule{}, err
+			}
+			rule.Targets = append(rule.Targets, pattern)
+		}
+		return rule, nil
+	}
+	return Rule{}, fmt.Errorf("cannot parse %q, expected \"<source> must not depend on <target>\", \"<source> may only depend on <target>\" or \"<source> may depend on <target>\"", line)
+}
+
+// Check returns the dependencies of report that violate the rules, ordered by
+// source and target. A dependency that breaks several rules is reported once,
+// for the first of them. A leaf may always depend on itself.
+func (s *RuleSet) Check(report *cgjson.ProjectReport) []Violation {
+	var violations []Violation
+	for edge := range report.Edges() {
+		if edge.IsSelf() {
+			continue
+		}
+		source, ok := s.relative(edge.Source)
+		if !ok {
+			continue
+		}
+		target, ok := s.relative(edge.Target)
+		if !ok {
+			target = edge.Target
+		}
+		if rule, violated := s.violatedRule(source, target); violated {
+			violations = append(violations, Violation{Edge: edge, Rule: rule})
+		}
+	}
+	return violations
+}
+
+// Unmatched returns the rules whose source pattern matches no leaf of report,
+// which usually means a typo or an outdated rule.
+func (s *RuleSet) Unmatched(report *cgjson.ProjectReport) []Rule {
+	var unmatched []Rule
+	ids := report.LeafIDs()
+	for _, rule := range s.Rules {
+		matched := false
+		for _, id := range ids {
+			if relative, ok := s.relative(id); ok && rule.Source.Matches(relative) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			unmatched = append(unmatched, rule)
+		}
+	}
+	return unmatched
+}
+
+func (s *RuleSet) violatedRule(source, target string) (Rule, bool) {
+	for _, rule := range s.Rules {
+		if rule.Kind == Allowed && rule.Source.Matches(source) && rule.matchesTarget(target) {
+			return Rule{}, false
+		}
+	}
+	for _, rule := range s.Rules {
+		if !rule.Source.Matches(source) {
+			continue
+		}
+		switch rule.Kind {
+		case Forbidden:
+			if rule.matchesTarget(target) {
+				return rule, true
+			}
+		case Exclusive:
+			if !rule.matchesTarget(target) {
+				return rule, true
+			}
+		}
+	}
+	return Rule{}, false
+}
+
+func (r Rule) matchesTarget(id string) bool {
+	for _, target := range r.Targets {
+		if target.Matches(id) {
+			return true
+		}
+	}
+	return false
+}
+
+// relative strips the prefix from id and reports whether id lies below it.
+func (s *RuleSet) relative(id string) (string, bool) {
+	if s.Prefix == "" {
+		return id, true
+	}
+	return strings.CutPrefix(id, s.Prefix+".")
+}
@@ -0,0 +1,156 @@
+package archrules
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson/cgjsontest"
+)
+
+func parse(t *testing.T, text string) *RuleSet {
+	t.Helper()
+	rules, err := Parse(strings.NewReader(text), "test.rules")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return rules
+}
+
+func describe(violations []Violation) []string {
+	descriptions := []string{}
+	for _, violation := range violations {
+		descriptions = append(descriptions, violation.String())
+	}
+	return descriptions
+}
+
+func TestParse(t *testing.T) {
+	// given
+	text := "# layers\n\nprefix app\ndomain.** must not depend on adapter.**, application.**\n"
+
+	// when
+	rules := parse(t, text)
+
+	// then
+	if rules.Prefix != "app" {
+		t.Errorf("prefix: got %q", rules.Prefix)
+	}
+	if len(rules.Rules) != 1 {
+		t.Fatalf("got %d rules", len(rules.Rules))
+	}
+	rule := rules.Rules[0]
+	if rule.Kind != Forbidden || rule.String() != "domain.** must not depend on adapter.**, application.**" || rule.Location() != "test.rules:4" {
+		t.Errorf("got %+v", rule)
+	}
+}
+
+func TestParseReportsLineOfMalformedRule(t *testing.T) {
+	// given
+	text := "domain.** must not depend on adapter.**\ndomain.** should not use adapter.**\n"
+
+	// when
+	_, err := Parse(strings.NewReader(text), "test.rules")
+
+	// then
+	if err == nil || !strings.HasPrefix(err.Error(), "test.rules:2: ") {
+		t.Errorf("got %v", err)
+	}
+}
+
+func TestCheckForbiddenDependency(t *testing.T) {
+	// given
+	report := cgjsontest.Layered(t)
+	rules := parse(t, "prefix app\ndomain.** must not depend on adapter.**\n")
+
+	// when
+	violations := rules.Check(report)
+
+	// then
+	expected := []string{`app.domain.Model -> app.adapter.Db [FEEDBACK_LEAF_LEVEL] violates "domain.** must not depend on adapter.**" (test.rules:2)`}
+	if actual := describe(violations); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("got %q\nwant %q", actual, expected)
+	}
+}
+
+func TestCheckExclusiveDependencies(t *testing.T) {
+	// given
+	report := cgjsontest.Layered(t)
+	rules := parse(t, "app.adapter.* may only depend on app.domain.Repository\n")
+
+	// when
+	violations := rules.Check(report)
+
+	// then
+	expected := []string{
+		`app.adapter.Db -> app.domain.Model [CYCLIC] violates "app.adapter.* may only depend on app.domain.Repository" (test.rules:1)`,
+		`app.adapter.Http -> app.adapter.Db [REGULAR] violates "app.adapter.* may only depend on app.domain.Repository" (test.rules:1)`,
+	}
+	if actual := describe(violations); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("got %q\nwant %q", actual, expected)
+	}
+}
+
+func TestCheckAllowsSelfDependencies(t *testing.T) {
+	// given
+	report := cgjsontest.Layered(t)
+	report.Leaves["app.adapter.Http"].Dependencies["app.adapter.Http"] = cgjson.EdgeInfo{Weight: 1, Type: "usage"}
+	rules := parse(t, "prefix app\nadapter.Http may only depend on adapter.Db\n")
+
+	// when
+	violations := rules.Check(report)
+
+	// then
+	if len(violations) != 0 {
+		t.Errorf("got %q", describe(violations))
+	}
+}
+
+func TestCheckExceptionsTakePrecedence(t *testing.T) {
+	// given
+	report := cgjsontest.Layered(t)
+	rules := parse(t, "app.adapter.* may only depend on app.domain.Repository\napp.adapter.** may depend on app.adapter.**\n")
+
+	// when
+	violations := rules.Check(report)
+
+	// then
+	expected := []string{`app.adapter.Db -> app.domain.Model [CYCLIC] violates "app.adapter.* may only depend on app.domain.Repository" (test.rules:1)`}
+	if actual := describe(violations); !reflect.DeepEqual(actual, expected) {
+		t.Errorf("got %q\nwant %q", actual, expected)
+	}
+}
+
+func TestCheckSkipsLeavesOutsidePrefix(t *testing.T) {
+	// given
+	report := cgjsontest.Layered(t)
+	rules := parse(t, "prefix app.adapter\n** must not depend on **\n")
+
+	// when
+	violations := rules.Check(report)
+
+	// then
+	if len(violations) != 3 {
+		t.Errorf("got %q", describe(violations))
+	}
+	for _, violation := range violations {
+		if !strings.HasPrefix(violation.Source, "app.adapter.") {
+			t.Errorf("unexpected violation %s", violation)
+		}
+	}
+}
+
+func TestUnmatched(t *testing.T) {
+	// given
+	report := cgjsontest.Layered(t)
+	rules := parse(t, "prefix app\ndomain.** must not depend on adapter.**\ndomian.** must not depend on adapter.**\n")
+
+	// when
+	unmatched := rules.Unmatched(report)
+
+	// then
+	if len(unmatched) != 1 || unmatched[0].Line != 3 {
+		t.Errorf("got %+v", unmatched)
+	}
+}
@@ -0,0 +1,235 @@
+// Package archtest turns the cyclic and upward-pointing dependencies that
+// DependaCharta computes into architecture fitness functions for ordinary
+// go test suites:
+//
+//	func TestArchitecture(t *testing.T) {
+//		arch := archtest.Load(t, "../output/analysis.cg.json").Within("src.de.sots.cellarsandcentaurs")
+//		arch.AssertNoCycles(t, "domain")
+//		arch.AssertLevelBelow(t, "domain", "adapter")
+//		arch.AssertNoUpwardDependencies(t, "application.CreatureFacade")
+//	}
+//
+// Selectors are leaf ids or namespace paths; a namespace stands for all
+// leaves below it. A failed assertion reports a test error listing the
+// offending dependencies, and a selector that matches nothing is an error as
+// well, so that rules do not silently outlive renamed code.
+package archtest
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+)
+
+// T is the subset of testing.TB the assertions use.
+type T interface {
+	Helper()
+	Errorf(format string, args ...any)
+	Fatalf(format string, args ...any)
+}
+
+// Architecture is a loaded analysis to make assertions about.
+type Architecture struct {
+	report *cgjson.ProjectReport
+	index  *cgjson.Index
+	edges  []cgjson.Edge
+	prefix string
+}
+
+// Load reads the .cg.json file at path, which is relative to the directory of
+// the test package, and stops the test if it cannot be read.
+func Load(t T, path string) *Architecture {
+	t.Helper()
+	report, err := cgjson.ReadFile(path)
+	if err != nil {
+		t.Fatalf("loading architecture: %v", err)
+		return nil
+	}
+	return New(report)
+}
+
+// New creates an Architecture for an analysis that is already loaded.
+func New(report *cgjson.ProjectReport) *Architecture {
+	a := &Architecture{report: report, index: cgjson.NewIndex(report)}
+	for edge := range report.Edges() {
+		a.edges = append(a.edges, edge)
+	}
+	return a
+}
+
+// Within returns a view of the architecture whose selectors are relative to
+// the namespace prefix.
+func (a *Architecture) Within(prefix string) *Architecture {
+	within := *a
+	within.prefix = a.qualify(prefix)
+	return &within
+}
+
+// AssertNoCycles fails if there are cyclic dependencies between the leaves
+// selected by selector.
+func (a *Architecture) AssertNoCycles(t T, selector string) {
+	t.Helper()
+	leaves, ok := a.resolve(t, selector)
+	if !ok {
+		return
+	}
+	a.fail(t, selector, "cyclic", func(edge cgjson.Edge) bool {
+		return edge.IsCyclic && leaves[edge.Source] && leaves[edge.Target]
+	})
+}
+
+// AssertNoUpwardDependencies fails if any leaf selected by selector has a
+// dependency that points upwards, i.e. against the levels of the
+// architecture.
+func (a *Architecture) AssertNoUpwardDependencies(t T, selector string) {
+	t.Helper()
+	leaves, ok := a.resolve(t, selector)
+	if !ok {
+		return
+	}
+	a.fail(t, selector, "upward-pointing", func(edge cgjson.Edge) bool {
+		return edge.IsPointingUpwards && leaves[edge.Source]
+	})
+}
+
+// AssertLevelBelow fails unless the node selected by lower has a lower level
+// than the one selected by higher. Levels are only defined among siblings, so
+// like in the analysis the levels of the ancestors of both nodes that are
+// children of their lowest common ancestor are compared. Nodes containing
+// each other have no such ancestors, which fails as well. The error lists the
+// dependencies from lower to higher, which keep lower from being below.
+func (a *Architecture) AssertLevelBelow(t T, lower, higher string) {
+	t.Helper()
+	lowerNode, lowerFound := a.node(t, lower)
+	higherNode, higherFound := a.node(t, higher)
+	if !lowerFound || !higherFound {
+		return
+	}
+	lowerSibling, higherSibling, ok := a.siblings(lowerNode, higherNode)
+	if !ok {
+		t.Errorf("%s and %s are not in different subtrees, so their levels cannot be compared", lower, higher)
+		return
+	}
+	if lowerSibling.Level < higherSibling.Level {
+		return
+	}
+	message := fmt.Sprintf("level of %s is not below level of %s", a.describe(lower, lowerNode, lowerSibling), a.describe(higher, higherNode, higherSibling))
+	lowerLeaves, higherLeaves := a.leaves(lowerNode), a.leaves(higherNode)
+	offending := a.filter(func(edge cgjson.Edge) bool {
+		return lowerLeaves[edge.Source] && higherLeaves[edge.Target]
+	})
+	if len(offending) == 0 {
+		t.Errorf("%s; %s does not depend on %s directly", message, lower, higher)
+		return
+	}
+	t.Errorf("%s; %s has %d %s on it:%s", message, lower, len(offending), dependencies(len(offending)), list(offending))
+}
+
+// siblings returns the ancestors of first and second, or the nodes
+// themselves, that are children of their lowest common ancestor. Roots count
+// as children of a common virtual root. ok is false if one node contains the
+// other.
+func (a *Architecture) siblings(first, second *cgjson.ProjectNode) (*cgjson.ProjectNode, *cgjson.ProjectNode, bool) {
+	firstLine, secondLine := a.lineage(first), a.lineage(second)
+	for i := 0; i < len(firstLine) && i < len(secondLine); i++ {
+		if firstLine[i] != secondLine[i] {
+			return firstLine[i], secondLine[i], true
+		}
+	}
+	return nil, nil, false
+}
+
+// lineage returns the nodes from the root down to node.
+func (a *Architecture) lineage(node *cgjson.ProjectNode) []*cgjson.ProjectNode {
+	ancestors := a.index.Ancestors(node)
+	line := make([]*cgjson.ProjectNode, 0, len(ancestors)+1)
+	for i := len(ancestors) - 1; i >= 0; i-- {
+		line = append(line, ancestors[i])
+	}
+	return append(line, node)
+}
+
+// describe names the selected node and the level compared for it, which is
+// the level of the ancestor sibling if the node is nested deeper.
+func (a *Architecture) describe(selector string, node, sibling *cgjson.ProjectNode) string {
+	if node == sibling {
+		return fmt.Sprintf("%s (%d)", selector, node.Level)
+	}
+	return fmt.Sprintf("%s in %s (%d)", selector, a.index.Path(sibling), sibling.Level)
+}
+
+// fail reports the edges matching offending as the kind of dependencies
+// selector has, if there are any.
+func (a *Architecture) fail(t T, selector, kind string, offending func(cgjson.Edge) bool) {
+	t.Helper()
+	if edges := a.filter(offending); len(edges) > 0 {
+		t.Errorf("%s has %d %s %s:%s", selector, len(edges), kind, dependencies(len(edges)), list(edges))
+	}
+}
+
+func (a *Architecture) filter(keep func(cgjson.Edge) bool) []cgjson.Edge {
+	var edges []cgjson.Edge
+	for _, edge := range a.edges {
+		if keep(edge) {
+			edges = append(edges, edge)
+		}
+	}
+	return edges
+}
+
+// resolve returns the ids of the leaves selected by selector.
+func (a *Architecture) resolve(t T, selector string) (map[string]bool, bool) {
+	t.Helper()
+	node, found := a.node(t, selector)
+	if !found {
+		return nil, false
+	}
+	return a.leaves(node), true
+}
+
+func (a *Architecture) node(t T, selector string) (*cgjson.ProjectNode, bool) {
+	t.Helper()
+	node, found := a.index.Node(a.qualify(selector))
+	if !found {
+		t.Errorf("no leaf or namespace %s in the architecture", a.qualify(selector))
+	}
+	return node, found
+}
+
+func (a *Architecture) leaves(node *cgjson.ProjectNode) map[string]bool {
+	leaves := make(map[string]bool, len(node.ContainedLeaves))
+	for _, id := range node.ContainedLeaves {
+		leaves[id] = true
+	}
+	if node.IsLeaf() {
+		leaves[*node.LeafID] = true
+	}
+	return leaves
+}
+
+func (a *Architecture) qualify(selector string) string {
+	if a.prefix == "" {
+		return selector
+	}
+	if selector == "" {
+		return a.prefix
+	}
+	return a.prefix + "." + selector
+}
+
+func dependencies(count int) string {
+	if count == 1 {
+		return "dependency"
+	}
+	return "dependencies"
+}
+
+// list formats edges one per line, indented to stand out in test output.
+func list(edges []cgjson.Edge) string {
+	var b strings.Builder
+	for _, edge := range edges {
+		fmt.Fprintf(&b, "\n\t%s -> %s [%s] (weight %d)", edge.Source, edge.Target, edge.EdgeType(), edge.Weight)
+	}
+	return b.String()
+}
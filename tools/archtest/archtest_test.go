@@ -0,0 +1,132 @@
+package archtest
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson/cgjsontest"
+)
+
+// recorder is a T that records the failures instead of reporting them.
+type recorder struct {
+	errors []string
+	fatal  bool
+}
+
+func (r *recorder) Helper() {}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.Errorf(format, args...)
+	r.fatal = true
+}
+
+func TestAssertNoCyclesListsCyclicDependencies(t *testing.T) {
+	// given
+	arch, r := Load(t, cgjsontest.LayeredFile(t)), &recorder{}
+
+	// when
+	arch.AssertNoCycles(r, "app.domain")
+	arch.AssertNoCycles(r, "app.adapter")
+
+	// then
+	expected := []string{"app.domain has 2 cyclic dependencies:" +
+		"\n\tapp.domain.Model -> app.domain.Repository [CYCLIC] (weight 1)" +
+		"\n\tapp.domain.Repository -> app.domain.Model [FEEDBACK_LEAF_LEVEL] (weight 1)"}
+	if !reflect.DeepEqual(r.errors, expected) {
+		t.Errorf("got %q", r.errors)
+	}
+}
+
+func TestAssertNoUpwardDependenciesOfLeaf(t *testing.T) {
+	// given
+	arch, r := Load(t, cgjsontest.LayeredFile(t)).Within("app"), &recorder{}
+
+	// when
+	arch.AssertNoUpwardDependencies(r, "domain.Model")
+	arch.AssertNoUpwardDependencies(r, "adapter")
+
+	// then
+	expected := []string{"domain.Model has 1 upward-pointing dependency:" +
+		"\n\tapp.domain.Model -> app.adapter.Db [FEEDBACK_LEAF_LEVEL] (weight 2)"}
+	if !reflect.DeepEqual(r.errors, expected) {
+		t.Errorf("got %q", r.errors)
+	}
+}
+
+func TestAssertLevelBelowListsDependenciesAgainstTheLevels(t *testing.T) {
+	// given
+	arch, r := Load(t, cgjsontest.LayeredFile(t)).Within("app"), &recorder{}
+
+	// when
+	arch.AssertLevelBelow(r, "domain", "adapter")
+	arch.AssertLevelBelow(r, "adapter", "domain")
+
+	// then
+	expected := []string{"level of adapter (1) is not below level of domain (0); adapter has 2 dependencies on it:" +
+		"\n\tapp.adapter.Db -> app.domain.Model [CYCLIC] (weight 3)" +
+		"\n\tapp.adapter.Db -> app.domain.Repository [CYCLIC] (weight 1)"}
+	if !reflect.DeepEqual(r.errors, expected) {
+		t.Errorf("got %q", r.errors)
+	}
+}
+
+func TestUnknownSelectorFails(t *testing.T) {
+	// given
+	arch, r := Load(t, cgjsontest.LayeredFile(t)), &recorder{}
+
+	// when
+	arch.AssertNoCycles(r, "app.web")
+
+	// then
+	if !reflect.DeepEqual(r.errors, []string{"no leaf or namespace app.web in the architecture"}) {
+		t.Errorf("got %q", r.errors)
+	}
+}
+
+func TestLoadStopsTestForMissingFile(t *testing.T) {
+	// given
+	r := &recorder{}
+
+	// when
+	Load(r, "missing.cg.json")
+
+	// then
+	if !r.fatal {
+		t.Error("expected the test to be stopped")
+	}
+}
+
+func TestAssertLevelBelowComparesAncestorsInDifferentSubtrees(t *testing.T) {
+	// given
+	arch, r := Load(t, cgjsontest.LayeredFile(t)).Within("app"), &recorder{}
+
+	// when
+	arch.AssertLevelBelow(r, "domain.Model", "adapter.Db")
+	arch.AssertLevelBelow(r, "adapter.Db", "domain.Model")
+
+	// then
+	expected := []string{"level of adapter.Db in app.adapter (1) is not below level of domain.Model in app.domain (0); adapter.Db has 1 dependency on it:" +
+		"\n\tapp.adapter.Db -> app.domain.Model [CYCLIC] (weight 3)"}
+	if !reflect.DeepEqual(r.errors, expected) {
+		t.Errorf("got %q", r.errors)
+	}
+}
+
+func TestAssertLevelBelowRejectsNestedNodes(t *testing.T) {
+	// given
+	arch, r := Load(t, cgjsontest.LayeredFile(t)).Within("app"), &recorder{}
+
+	// when
+	arch.AssertLevelBelow(r, "domain", "domain.Model")
+
+	// then
+	expected := []string{"domain and domain.Model are not in different subtrees, so their levels cannot be compared"}
+	if !reflect.DeepEqual(r.errors, expected) {
+		t.Errorf("got %q", r.errors)
+	}
+}
@@ -0,0 +1,65 @@
+package cgjson
+
+import (
+	"iter"
+	"sort"
+	"strings"
+)
+
+// Aggregate merges edges whose source and target map to the same pair under
+// group, e.g. to the namespaces they belong to when the tree is collapsed.
+// The weights of the merged edges are summed and isCyclic and
+// isPointingUpwards are set if any merged edge has them. Type is kept if all
+// merged edges share it and empty otherwise. Edges that group maps onto a
+// single node are dropped. The result is ordered by source and target.
+func Aggregate(edges iter.Seq[Edge], group func(id string) string) []Edge {
+	type key struct{ source, target string }
+	merged := make(map[key]*Edge)
+	for edge := range edges {
+		k := key{group(edge.Source), group(edge.Target)}
+		if k.source == k.target {
+			continue
+		}
+		existing, ok := merged[k]
+		if !ok {
+			merged[k] = &Edge{Source: k.source, Target: k.target, EdgeInfo: edge.EdgeInfo}
+			continue
+		}
+		existing.Weight += edge.Weight
+		existing.IsCyclic = existing.IsCyclic || edge.IsCyclic
+		existing.IsPointingUpwards = existing.IsPointingUpwards || edge.IsPointingUpwards
+		if existing.Type != edge.Type {
+			existing.Type = ""
+		}
+	}
+
+	aggregated := make([]Edge, 0, len(merged))
+	for _, edge := range merged {
+		aggregated = append(aggregated, *edge)
+	}
+	sort.Slice(aggregated, func(i, j int) bool {
+		if aggregated[i].Source != aggregated[j].Source {
+			return aggregated[i].Source < aggregated[j].Source
+		}
+		return aggregated[i].Target < aggregated[j].Target
+	})
+	return aggregated
+}
+
+// Truncate shortens the dot-separated path to its first depth segments, e.g.
+// Truncate("de.sots.domain.Model", 2) is "de.sots". Paths with at most depth
+// segments and depths below 1 leave the path unchanged.
+func Truncate(path string, depth int) string {
+	if depth < 1 {
+		return path
+	}
+	end := 0
+	for range depth {
+		next := strings.IndexByte(path[end:], '.')
+		if next == -1 {
+			return path
+		}
+		end += next + 1
+	}
+	return path[:end-1]
+}
@@ -0,0 +1,66 @@
+package cgjson
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestAggregateByNamespace(t *testing.T) {
+	// given
+	report := readLayered(t)
+	index := NewIndex(report)
+
+	// when
+	edges := Aggregate(report.Edges(), index.Namespace)
+
+	// then
+	expected := []Edge{
+		{Source: "app.adapter", Target: "app.domain", EdgeInfo: EdgeInfo{IsCyclic: true, Weight: 4}},
+		{Source: "app.domain", Target: "app.adapter", EdgeInfo: EdgeInfo{IsCyclic: true, Weight: 2, Type: "usage", IsPointingUpwards: true}},
+	}
+	if !reflect.DeepEqual(edges, expected) {
+		t.Errorf("got %+v\nwant %+v", edges, expected)
+	}
+}
+
+func TestAggregateKeepsFlagsOfAnyMergedEdge(t *testing.T) {
+	// given
+	report := readLayered(t)
+	group := func(id string) string {
+		if id == "app.domain.Model" {
+			return id
+		}
+		return "rest"
+	}
+
+	// when
+	edges := Aggregate(report.Edges(), group)
+
+	// then
+	expected := []Edge{
+		{Source: "app.domain.Model", Target: "rest", EdgeInfo: EdgeInfo{IsCyclic: true, Weight: 3, Type: "usage", IsPointingUpwards: true}},
+		{Source: "rest", Target: "app.domain.Model", EdgeInfo: EdgeInfo{IsCyclic: true, Weight: 4, IsPointingUpwards: true}},
+	}
+	if !reflect.DeepEqual(edges, expected) {
+		t.Errorf("got %+v\nwant %+v", edges, expected)
+	}
+}
+
+func TestTruncate(t *testing.T) {
+	cases := []struct {
+		path     string
+		depth    int
+		expected string
+	}{
+		{"de.sots.domain.Model", 2, "de.sots"},
+		{"de.sots.domain.Model", 4, "de.sots.domain.Model"},
+		{"de.sots.domain.Model", 9, "de.sots.domain.Model"},
+		{"de.sots.domain.Model", 0, "de.sots.domain.Model"},
+		{"Model", 1, "Model"},
+	}
+	for _, c := range cases {
+		if actual := Truncate(c.path, c.depth); actual != c.expected {
+			t.Errorf("Truncate(%q, %d): got %q, want %q", c.path, c.depth, actual, c.expected)
+		}
+	}
+}
@@ -0,0 +1,63 @@
+// Package cgjsontest provides the small analysis the tests of cgjson and of
+// the tools built on it share, so that the fixture lives in one place.
+//
+// The layered analysis has the namespaces app.domain (level 0) and
+// app.adapter (level 1). app.domain.Model and app.domain.Repository depend on
+// each other, Model depends upwards on app.adapter.Db and Db depends on both
+// domain leaves, so the three of them form a cycle. app.adapter.Http depends
+// on Db. Levels and flags are those cgjson.Recompute computes.
+package cgjsontest
+
+import (
+	"bytes"
+	_ "embed"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+)
+
+//go:embed layered.cg.json
+var layered []byte
+
+// Layered returns a new copy of the layered analysis, which the test may
+// modify.
+func Layered(t testing.TB) *cgjson.ProjectReport {
+	t.Helper()
+	report, err := cgjson.Decode(bytes.NewReader(layered))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
+
+// Decoupled returns the layered analysis in which app.adapter.Http instead of
+// Db depends on the domain, on Repository. Levels and flags are recomputed, so
+// the only cycle left is the one of Model and Repository, and
+// Http -> Repository points upwards without being part of a cycle.
+func Decoupled(t testing.TB) *cgjson.ProjectReport {
+	t.Helper()
+	report := Layered(t)
+	clear(report.Leaves["app.adapter.Db"].Dependencies)
+	report.Leaves["app.adapter.Http"].Dependencies["app.domain.Repository"] = cgjson.EdgeInfo{Weight: 1, Type: "usage"}
+	report.Recompute()
+	return report
+}
+
+// WriteLayered writes the layered analysis as written by the export to path,
+// for tests of code that reads .cg.json files, and returns path.
+func WriteLayered(t testing.TB, path string) string {
+	t.Helper()
+	if err := os.WriteFile(path, layered, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// LayeredFile writes the layered analysis into a temporary directory and
+// returns the path of the file.
+func LayeredFile(t testing.TB) string {
+	t.Helper()
+	return WriteLayered(t, filepath.Join(t.TempDir(), "layered.cg.json"))
+}
@@ -0,0 +1,77 @@
+package cgjson
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Decode reads a ProjectReport from r.
+func Decode(r io.Reader) (*ProjectReport, error) {
+	var report ProjectReport
+	if err := json.NewDecoder(r).Decode(&report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// Encode writes report to w in the compact format of ExportService.toJson.
+func Encode(w io.Writer, report *ProjectReport) error {
+	content, err := marshal(report)
+	if err != nil {
+		return err
+	}
+	_, err = w.Write(content)
+	return err
+}
+
+// ReadFile decodes the .cg.json file at path.
+func ReadFile(path string) (*ProjectReport, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	report, err := Decode(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return report, nil
+}
+
+// WriteFile encodes report into the .cg.json file at path.
+func WriteFile(path string, report *ProjectReport) error {
+	return CreateFile(path, false, func(w io.Writer) error {
+		return Encode(w, report)
+	})
+}
+
+// CreateFile creates the file at path and lets write fill it, compressed with
+// gzip if compress is set. The gzip writer and the file are closed
+// explicitly, as a failed flush or a missing gzip footer would otherwise leave
+// a truncated file behind unnoticed. The first error is returned.
+func CreateFile(path string, compress bool, write func(io.Writer) error) error {
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	var out io.Writer = file
+	var compressed *gzip.Writer
+	if compress {
+		compressed = gzip.NewWriter(file)
+		out = compressed
+	}
+	err = write(out)
+	if compressed != nil {
+		if closeErr := compressed.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
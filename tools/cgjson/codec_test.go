@@ -0,0 +1,185 @@
+package cgjson
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// exportedFiles were written by ExportService.toJson. java-example.cg.json is
+// the expected output of ProcessingPipelineTest.
+var exportedFiles = []string{
+	"../../analysis/src/test/resources/pipeline/projectreport/java-example.cg.json",
+	"../../visualization/public/resources/go-example.cg.json",
+	"../../visualization/public/resources/typescript-example.cg.json",
+	layered,
+}
+
+func TestRoundTripPreservesExportedFiles(t *testing.T) {
+	for _, path := range exportedFiles {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			// given
+			original, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			// when
+			report, err := Decode(bytes.NewReader(original))
+			if err != nil {
+				t.Fatal(err)
+			}
+			var encoded bytes.Buffer
+			if err := Encode(&encoded, report); err != nil {
+				t.Fatal(err)
+			}
+
+			// then
+			if !reflect.DeepEqual(genericJSON(t, original), genericJSON(t, encoded.Bytes())) {
+				t.Errorf("re-encoded %s differs from the original", path)
+			}
+		})
+	}
+}
+
+func TestTypesMirrorAllExportedFields(t *testing.T) {
+	for _, path := range exportedFiles {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			// given
+			original, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+			decoder := json.NewDecoder(bytes.NewReader(original))
+			decoder.DisallowUnknownFields()
+
+			// when
+			var report ProjectReport
+			err = decoder.Decode(&report)
+
+			// then
+			if err != nil {
+				t.Errorf("unknown field in %s: %v", path, err)
+			}
+		})
+	}
+}
+
+func TestEncodeWritesEmptyCollectionsLikeTheKotlinExport(t *testing.T) {
+	// given
+	report := &ProjectReport{
+		ProjectTreeRoots: []*ProjectNode{{Name: "root"}},
+		Leaves:           map[string]*LeafInformation{"root.A": {ID: "root.A", Name: "A"}},
+	}
+
+	// when
+	var encoded bytes.Buffer
+	if err := Encode(&encoded, report); err != nil {
+		t.Fatal(err)
+	}
+
+	// then
+	expected := `{"projectTreeRoots":[{"name":"root","children":[],"level":0,"containedLeaves":[],"containedInternalDependencies":{}}],` +
+		`"leaves":{"root.A":{"id":"root.A","name":"A","physicalPath":"","nodeType":"","language":"","dependencies":{}}}}`
+	if encoded.String() != expected {
+		t.Errorf("got\n%s\nwant\n%s", encoded.String(), expected)
+	}
+}
+
+func TestEncodeDoesNotEscapeGenericTypeIds(t *testing.T) {
+	// given
+	report := &ProjectReport{Leaves: map[string]*LeafInformation{"a.List<T>": {ID: "a.List<T>"}}}
+
+	// when
+	var encoded bytes.Buffer
+	if err := Encode(&encoded, report); err != nil {
+		t.Fatal(err)
+	}
+
+	// then
+	if !strings.Contains(encoded.String(), `"a.List<T>"`) {
+		t.Errorf("expected unescaped id in %s", encoded.String())
+	}
+}
+
+func TestWriteFileAndReadFileRoundTrip(t *testing.T) {
+	// given
+	report, err := ReadFile(layered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path := filepath.Join(t.TempDir(), "analysis.cg.json")
+
+	// when
+	if err := WriteFile(path, report); err != nil {
+		t.Fatal(err)
+	}
+	reread, err := ReadFile(path)
+
+	// then
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(report, reread) {
+		t.Error("report changed after writing and reading it")
+	}
+}
+
+func genericJSON(t *testing.T, content []byte) any {
+	t.Helper()
+	var value any
+	if err := json.Unmarshal(content, &value); err != nil {
+		t.Fatal(err)
+	}
+	return value
+}
+
+func TestCreateFileCompressesTheCompleteFile(t *testing.T) {
+	// given
+	path := filepath.Join(t.TempDir(), "analysis.json.gz")
+
+	// when
+	err := CreateFile(path, true, func(w io.Writer) error {
+		_, err := io.WriteString(w, "content")
+		return err
+	})
+
+	// then
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(reader)
+	if err != nil || string(content) != "content" {
+		t.Errorf("got %q and %v", content, err)
+	}
+}
+
+func TestCreateFileReturnsTheErrorOfWrite(t *testing.T) {
+	// given
+	path := filepath.Join(t.TempDir(), "analysis.cg.json")
+	failure := errors.New("encoding failed")
+
+	// when
+	err := CreateFile(path, false, func(io.Writer) error { return failure })
+
+	// then
+	if err != failure {
+		t.Errorf("got %v", err)
+	}
+}
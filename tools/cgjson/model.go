@@ -0,0 +1,152 @@
+// Package cgjson reads and writes the .cg.json files produced by the
+// DependaCharta analysis and offers helpers to navigate them.
+//
+// The types mirror the Kotlin DTOs in
+// de.maibornwolff.dependacharta.pipeline.processing.model field by field:
+// ProjectReport is ProjectReportDto, ProjectNode is ProjectNodeDto,
+// LeafInformation is LeafInformationDto and EdgeInfo is EdgeInfoDto.
+// ProjectNode additionally has the optional Attributes, which are only
+// written if set.
+package cgjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// ProjectReport is the root of a .cg.json file.
+type ProjectReport struct {
+	ProjectTreeRoots []*ProjectNode              `json:"projectTreeRoots"`
+	Leaves           map[string]*LeafInformation `json:"leaves"`
+}
+
+// ProjectNode is a namespace or, if LeafID is set, a leaf in the project tree.
+type ProjectNode struct {
+	LeafID                        *string             `json:"leafId,omitempty"`
+	Name                          string              `json:"name"`
+	Children                      []*ProjectNode      `json:"children"`
+	Level                         int                 `json:"level"`
+	ContainedLeaves               []string            `json:"containedLeaves"`
+	ContainedInternalDependencies map[string]EdgeInfo `json:"containedInternalDependencies"`
+	// Attributes holds additional per-node values such as metrics written by
+	// the tools in this module. The analysis does not produce it and the
+	// visualization ignores it.
+	Attributes map[string]float64 `json:"attributes,omitempty"`
+}
+
+// LeafInformation describes a leaf and its outgoing dependencies, keyed by the
+// id of the target leaf.
+type LeafInformation struct {
+	ID           string              `json:"id"`
+	Name         string              `json:"name"`
+	PhysicalPath string              `json:"physicalPath"`
+	NodeType     string              `json:"nodeType"`
+	Language     string              `json:"language"`
+	Dependencies map[string]EdgeInfo `json:"dependencies"`
+}
+
+// EdgeInfo describes a dependency. Type is the kind of usage, e.g. "usage" or
+// "inheritance", not the EdgeType.
+type EdgeInfo struct {
+	IsCyclic          bool   `json:"isCyclic"`
+	Weight            int    `json:"weight"`
+	Type              string `json:"type"`
+	IsPointingUpwards bool   `json:"isPointingUpwards"`
+}
+
+// EdgeType is the classification of an edge used by the visualization to
+// colour it, see DOMAIN.md.
+type EdgeType string
+
+const (
+	Regular                EdgeType = "REGULAR"
+	Cyclic                 EdgeType = "CYCLIC"
+	FeedbackContainerLevel EdgeType = "FEEDBACK_CONTAINER_LEVEL"
+	FeedbackLeafLevel      EdgeType = "FEEDBACK_LEAF_LEVEL"
+)
+
+// EdgeType classifies the edge by its isCyclic and isPointingUpwards flags.
+func (e EdgeInfo) EdgeType() EdgeType {
+	switch {
+	case e.IsCyclic && e.IsPointingUpwards:
+		return FeedbackLeafLevel
+	case e.IsPointingUpwards:
+		return FeedbackContainerLevel
+	case e.IsCyclic:
+		return Cyclic
+	default:
+		return Regular
+	}
+}
+
+// Describe explains in one sentence what is wrong with edge according to its
+// edge type, e.g. for diagnostics of editors and code scanners.
+func Describe(edge Edge) string {
+	switch edge.EdgeType() {
+	case FeedbackLeafLevel:
+		return fmt.Sprintf("%s depends on %s, which points upwards and creates a cycle at leaf level.", edge.Source, edge.Target)
+	case FeedbackContainerLevel:
+		return fmt.Sprintf("%s depends on %s, which points upwards in the architecture.", edge.Source, edge.Target)
+	case Cyclic:
+		return fmt.Sprintf("%s depends on %s, which is part of a dependency cycle.", edge.Source, edge.Target)
+	default:
+		return fmt.Sprintf("%s depends on %s.", edge.Source, edge.Target)
+	}
+}
+
+// IsLeaf reports whether the node represents a leaf.
+func (n *ProjectNode) IsLeaf() bool {
+	return n.LeafID != nil
+}
+
+// MarshalJSON writes empty collections as [] and {} like the Kotlin export
+// instead of null.
+func (r ProjectReport) MarshalJSON() ([]byte, error) {
+	type plain ProjectReport
+	if r.ProjectTreeRoots == nil {
+		r.ProjectTreeRoots = []*ProjectNode{}
+	}
+	if r.Leaves == nil {
+		r.Leaves = map[string]*LeafInformation{}
+	}
+	return marshal(plain(r))
+}
+
+// MarshalJSON writes empty collections as [] and {} like the Kotlin export
+// instead of null.
+func (n ProjectNode) MarshalJSON() ([]byte, error) {
+	type plain ProjectNode
+	if n.Children == nil {
+		n.Children = []*ProjectNode{}
+	}
+	if n.ContainedLeaves == nil {
+		n.ContainedLeaves = []string{}
+	}
+	if n.ContainedInternalDependencies == nil {
+		n.ContainedInternalDependencies = map[string]EdgeInfo{}
+	}
+	return marshal(plain(n))
+}
+
+// MarshalJSON writes empty collections as {} like the Kotlin export instead of
+// null.
+func (l LeafInformation) MarshalJSON() ([]byte, error) {
+	type plain LeafInformation
+	if l.Dependencies == nil {
+		l.Dependencies = map[string]EdgeInfo{}
+	}
+	return marshal(plain(l))
+}
+
+// marshal encodes v without escaping <, > and &, which may appear in leaf ids
+// of generic types.
+func marshal(v any) ([]byte, error) {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(v); err != nil {
+		return nil, err
+	}
+	return bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), nil
+}
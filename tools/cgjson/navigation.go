@@ -0,0 +1,183 @@
+package cgjson
+
+import (
+	"iter"
+	"sort"
+	"strings"
+)
+
+// Edge is a dependency from the leaf Source to the leaf Target.
+type Edge struct {
+	Source string
+	Target string
+	EdgeInfo
+}
+
+// IsSelf reports whether the edge is a dependency of a leaf on itself.
+func (e Edge) IsSelf() bool {
+	return e.Source == e.Target
+}
+
+// Leaf returns the leaf with the given id.
+func (r *ProjectReport) Leaf(id string) (*LeafInformation, bool) {
+	leaf, ok := r.Leaves[id]
+	return leaf, ok
+}
+
+// LeafIDs returns the ids of all leaves, sorted.
+func (r *ProjectReport) LeafIDs() []string {
+	ids := make([]string, 0, len(r.Leaves))
+	for id := range r.Leaves {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
+
+// Edges iterates over the dependencies of all leaves, ordered by source and
+// target id. The export only sets isPointingUpwards on the
+// containedInternalDependencies of the leaf nodes in the project tree, so the
+// flag is taken from there. A dependency of a leaf on itself never points
+// upwards: the export marks it as such only because a node's level is never
+// below its own.
+func (r *ProjectReport) Edges() iter.Seq[Edge] {
+	return func(yield func(Edge) bool) {
+		treeDependencies := make(map[string]map[string]EdgeInfo)
+		for node := range r.Nodes() {
+			if node.IsLeaf() {
+				treeDependencies[*node.LeafID] = node.ContainedInternalDependencies
+			}
+		}
+
+		for _, source := range r.LeafIDs() {
+			dependencies := r.Leaves[source].Dependencies
+			targets := make([]string, 0, len(dependencies))
+			for target := range dependencies {
+				targets = append(targets, target)
+			}
+			sort.Strings(targets)
+			for _, target := range targets {
+				info := dependencies[target]
+				if treeInfo, ok := treeDependencies[source][target]; ok {
+					info.IsPointingUpwards = info.IsPointingUpwards || treeInfo.IsPointingUpwards
+				}
+				if source == target {
+					info.IsPointingUpwards = false
+				}
+				if !yield(Edge{Source: source, Target: target, EdgeInfo: info}) {
+					return
+				}
+			}
+		}
+	}
+}
+
+// Nodes iterates over all nodes of the project tree in depth-first pre-order,
+// together with their parent, which is nil for the roots.
+func (r *ProjectReport) Nodes() iter.Seq2[*ProjectNode, *ProjectNode] {
+	return func(yield func(*ProjectNode, *ProjectNode) bool) {
+		var walk func(node, parent *ProjectNode) bool
+		walk = func(node, parent *ProjectNode) bool {
+			if !yield(node, parent) {
+				return false
+			}
+			for _, child := range node.Children {
+				if !walk(child, node) {
+					return false
+				}
+			}
+			return true
+		}
+		for _, root := range r.ProjectTreeRoots {
+			if !walk(root, nil) {
+				return
+			}
+		}
+	}
+}
+
+// Index answers structural questions about a project tree that the tree
+// itself cannot answer efficiently. It must be rebuilt if the tree changes.
+type Index struct {
+	parents   map[*ProjectNode]*ProjectNode
+	paths     map[*ProjectNode]string
+	leafNodes map[string]*ProjectNode
+	nodes     map[string]*ProjectNode
+}
+
+// NewIndex indexes the project tree of report.
+func NewIndex(report *ProjectReport) *Index {
+	index := &Index{
+		parents:   make(map[*ProjectNode]*ProjectNode),
+		paths:     make(map[*ProjectNode]string),
+		leafNodes: make(map[string]*ProjectNode),
+		nodes:     make(map[string]*ProjectNode),
+	}
+	for node, parent := range report.Nodes() {
+		index.parents[node] = parent
+		path := node.Name
+		if parent != nil {
+			path = index.paths[parent] + "." + node.Name
+		}
+		index.paths[node] = path
+		index.nodes[path] = node
+		if node.IsLeaf() {
+			index.leafNodes[*node.LeafID] = node
+		}
+	}
+	return index
+}
+
+// Parent returns the parent of node, or nil for a root.
+func (i *Index) Parent(node *ProjectNode) *ProjectNode {
+	return i.parents[node]
+}
+
+// Ancestors returns the parents of node from the direct parent up to the root.
+func (i *Index) Ancestors(node *ProjectNode) []*ProjectNode {
+	var ancestors []*ProjectNode
+	for parent := i.parents[node]; parent != nil; parent = i.parents[parent] {
+		ancestors = append(ancestors, parent)
+	}
+	return ancestors
+}
+
+// Path returns the dot-separated names from the root down to node, e.g.
+// "de.sots.cellarsandcentaurs.domain".
+func (i *Index) Path(node *ProjectNode) string {
+	return i.paths[node]
+}
+
+// Node returns the namespace or leaf node with the given dot-separated path.
+func (i *Index) Node(path string) (*ProjectNode, bool) {
+	node, ok := i.nodes[path]
+	return node, ok
+}
+
+// LeafNode returns the tree node of the leaf with the given id.
+func (i *Index) LeafNode(id string) (*ProjectNode, bool) {
+	node, ok := i.leafNodes[id]
+	return node, ok
+}
+
+// Namespace returns the path of the namespace containing the leaf with the
+// given id, or an empty string for a leaf at the root.
+func (i *Index) Namespace(leafID string) string {
+	node, ok := i.leafNodes[leafID]
+	if !ok {
+		namespace, _, _ := cutLast(leafID)
+		return namespace
+	}
+	if parent := i.parents[node]; parent != nil {
+		return i.paths[parent]
+	}
+	return ""
+}
+
+func cutLast(path string) (string, string, bool) {
+	index := strings.LastIndex(path, ".")
+	if index == -1 {
+		return "", path, false
+	}
+	return path[:index], path[index+1:], true
+}
@@ -0,0 +1,140 @@
+package cgjson
+
+import (
+	"reflect"
+	"testing"
+)
+
+// layered is the fixture of package cgjsontest, which the tests of this
+// package cannot import.
+const layered = "cgjsontest/layered.cg.json"
+
+func readLayered(t *testing.T) *ProjectReport {
+	t.Helper()
+	report, err := ReadFile(layered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return report
+}
+
+func TestEdgesAreOrderedAndTakeUpwardFlagFromTree(t *testing.T) {
+	// given
+	report := readLayered(t)
+
+	// when
+	var edges []Edge
+	for edge := range report.Edges() {
+		edges = append(edges, edge)
+	}
+
+	// then
+	expected := []Edge{
+		{Source: "app.adapter.Db", Target: "app.domain.Model", EdgeInfo: EdgeInfo{IsCyclic: true, Weight: 3, Type: "usage"}},
+		{Source: "app.adapter.Db", Target: "app.domain.Repository", EdgeInfo: EdgeInfo{IsCyclic: true, Weight: 1, Type: "implementation"}},
+		{Source: "app.adapter.Http", Target: "app.adapter.Db", EdgeInfo: EdgeInfo{Weight: 1, Type: "usage"}},
+		{Source: "app.domain.Model", Target: "app.adapter.Db", EdgeInfo: EdgeInfo{IsCyclic: true, Weight: 2, Type: "usage", IsPointingUpwards: true}},
+		{Source: "app.domain.Model", Target: "app.domain.Repository", EdgeInfo: EdgeInfo{IsCyclic: true, Weight: 1, Type: "usage"}},
+		{Source: "app.domain.Repository", Target: "app.domain.Model", EdgeInfo: EdgeInfo{IsCyclic: true, Weight: 1, Type: "return_value", IsPointingUpwards: true}},
+	}
+	if !reflect.DeepEqual(edges, expected) {
+		t.Errorf("got %+v\nwant %+v", edges, expected)
+	}
+}
+
+func TestEdgesNeverMarkSelfDependenciesAsUpward(t *testing.T) {
+	// given
+	report := readLayered(t)
+	report.Leaves["app.adapter.Http"].Dependencies["app.adapter.Http"] = EdgeInfo{Weight: 1, Type: "usage"}
+	node, _ := NewIndex(report).LeafNode("app.adapter.Http")
+	node.ContainedInternalDependencies["app.adapter.Http"] = EdgeInfo{Weight: 1, Type: "usage", IsPointingUpwards: true}
+
+	// when
+	var self []Edge
+	for edge := range report.Edges() {
+		if edge.IsSelf() {
+			self = append(self, edge)
+		}
+	}
+
+	// then
+	expected := []Edge{{Source: "app.adapter.Http", Target: "app.adapter.Http", EdgeInfo: EdgeInfo{Weight: 1, Type: "usage"}}}
+	if !reflect.DeepEqual(self, expected) {
+		t.Errorf("got %+v", self)
+	}
+}
+
+func TestEdgeType(t *testing.T) {
+	cases := map[EdgeInfo]EdgeType{
+		{}:                        Regular,
+		{IsCyclic: true}:          Cyclic,
+		{IsPointingUpwards: true}: FeedbackContainerLevel,
+		{IsCyclic: true, IsPointingUpwards: true}: FeedbackLeafLevel,
+	}
+	for info, expected := range cases {
+		if actual := info.EdgeType(); actual != expected {
+			t.Errorf("%+v: got %s, want %s", info, actual, expected)
+		}
+	}
+}
+
+func TestDescribe(t *testing.T) {
+	cases := map[EdgeInfo]string{
+		{}:                        "a depends on b.",
+		{IsCyclic: true}:          "a depends on b, which is part of a dependency cycle.",
+		{IsPointingUpwards: true}: "a depends on b, which points upwards in the architecture.",
+		{IsCyclic: true, IsPointingUpwards: true}: "a depends on b, which points upwards and creates a cycle at leaf level.",
+	}
+	for info, expected := range cases {
+		if actual := Describe(Edge{Source: "a", Target: "b", EdgeInfo: info}); actual != expected {
+			t.Errorf("%+v: got %q, want %q", info, actual, expected)
+		}
+	}
+}
+
+func TestIndexParentAndPaths(t *testing.T) {
+	// given
+	report := readLayered(t)
+
+	// when
+	index := NewIndex(report)
+
+	// then
+	leaf, ok := index.LeafNode("app.domain.Model")
+	if !ok {
+		t.Fatal("leaf node not found")
+	}
+	parent := index.Parent(leaf)
+	if parent == nil || index.Path(parent) != "app.domain" {
+		t.Errorf("unexpected parent %+v", parent)
+	}
+	if ancestors := index.Ancestors(leaf); len(ancestors) != 2 || ancestors[1] != report.ProjectTreeRoots[0] {
+		t.Errorf("unexpected ancestors %+v", ancestors)
+	}
+	if index.Parent(report.ProjectTreeRoots[0]) != nil {
+		t.Error("root must not have a parent")
+	}
+	if node, ok := index.Node("app.adapter"); !ok || node.Level != 1 {
+		t.Errorf("unexpected namespace %+v", node)
+	}
+	if namespace := index.Namespace("app.adapter.Http"); namespace != "app.adapter" {
+		t.Errorf("got namespace %q", namespace)
+	}
+}
+
+func TestLeafLookup(t *testing.T) {
+	// given
+	report := readLayered(t)
+
+	// when
+	leaf, ok := report.Leaf("app.domain.Repository")
+	_, missing := report.Leaf("app.domain.Unknown")
+
+	// then
+	if !ok || leaf.NodeType != "INTERFACE" {
+		t.Errorf("unexpected leaf %+v", leaf)
+	}
+	if missing {
+		t.Error("unknown leaf must not be found")
+	}
+}
@@ -0,0 +1,332 @@
+package cgjson
+
+import (
+	"slices"
+	"sort"
+	"strings"
+)
+
+// Recompute derives everything the analysis computes from the leaves and
+// their dependencies: isCyclic, the levels, isPointingUpwards, containedLeaves
+// and containedInternalDependencies. It is needed after leaves or
+// dependencies were added or removed, e.g. when merging analyses.
+//
+// A dependency is cyclic if its source and target are distinct and lie in the
+// same strongly connected component. Levels are computed per namespace like
+// the Levelizer of the analysis does: the dependencies between the leaves are
+// lifted to the direct children, cycles among them are broken by repeatedly
+// cutting the edge of a cycle that points to the child with the least incoming
+// weight, and each child's level is one above the highest level it depends
+// on. Unlike the analysis, which caps the length of the cycles it searches in
+// large tangles, every edge inside a strongly connected component is marked
+// cyclic, and ties are broken by tree order rather than by hash order.
+func (r *ProjectReport) Recompute() {
+	r.recomputeCycles()
+	root := &ProjectNode{Children: r.ProjectTreeRoots}
+	r.recomputeContainedLeaves(root)
+	r.recomputeLevels(root)
+
+	paths := make(map[string][]*ProjectNode)
+	var collect func(node *ProjectNode, path []*ProjectNode)
+	collect = func(node *ProjectNode, path []*ProjectNode) {
+		path = append(append([]*ProjectNode{}, path...), node)
+		if node.IsLeaf() {
+			paths[*node.LeafID] = path
+		}
+		for _, child := range node.Children {
+			collect(child, path)
+		}
+	}
+	collect(root, nil)
+	r.recomputeDependencies(root, paths)
+}
+
+// StronglyConnectedComponents returns the strongly connected components with
+// more than one node of the graph given by successors, using Tarjan's
+// algorithm. Each component is sorted and the components are ordered by their
+// first id.
+func StronglyConnectedComponents(successors map[string][]string) [][]string {
+	ids := make(map[string]int)
+	var names []string
+	number := func(id string) int {
+		if n, ok := ids[id]; ok {
+			return n
+		}
+		ids[id] = len(names)
+		names = append(names, id)
+		return ids[id]
+	}
+	for _, source := range sortedKeys(successors) {
+		number(source)
+		for _, target := range successors[source] {
+			number(target)
+		}
+	}
+	graph := make([][]int, len(names))
+	for source, targets := range successors {
+		for _, target := range targets {
+			graph[ids[source]] = append(graph[ids[source]], ids[target])
+		}
+	}
+
+	var components [][]string
+	for _, component := range tarjan(graph) {
+		if len(component) < 2 {
+			continue
+		}
+		members := make([]string, len(component))
+		for i, n := range component {
+			members[i] = names[n]
+		}
+		sort.Strings(members)
+		components = append(components, members)
+	}
+	sort.Slice(components, func(i, j int) bool { return components[i][0] < components[j][0] })
+	return components
+}
+
+// tarjan returns the strongly connected components of graph, including the
+// single-node ones.
+func tarjan(graph [][]int) [][]int {
+	index := make([]int, len(graph))
+	lowLink := make([]int, len(graph))
+	onStack := make([]bool, len(graph))
+	for i := range index {
+		index[i] = -1
+	}
+	var stack []int
+	var components [][]int
+	next := 0
+
+	var connect func(node int)
+	connect = func(node int) {
+		index[node], lowLink[node] = next, next
+		next++
+		stack = append(stack, node)
+		onStack[node] = true
+		for _, successor := range graph[node] {
+			if index[successor] == -1 {
+				connect(successor)
+				lowLink[node] = min(lowLink[node], lowLink[successor])
+			} else if onStack[successor] {
+				lowLink[node] = min(lowLink[node], index[successor])
+			}
+		}
+		if lowLink[node] == index[node] {
+			var component []int
+			for {
+				top := stack[len(stack)-1]
+				stack = stack[:len(stack)-1]
+				onStack[top] = false
+				component = append(component, top)
+				if top == node {
+					break
+				}
+			}
+			components = append(components, component)
+		}
+	}
+	for node := range graph {
+		if index[node] == -1 {
+			connect(node)
+		}
+	}
+	return components
+}
+
+func (r *ProjectReport) recomputeCycles() {
+	successors := make(map[string][]string, len(r.Leaves))
+	for id, leaf := range r.Leaves {
+		for target := range leaf.Dependencies {
+			if _, exists := r.Leaves[target]; exists && target != id {
+				successors[id] = append(successors[id], target)
+			}
+		}
+	}
+	component := make(map[string]int)
+	for i, members := range StronglyConnectedComponents(successors) {
+		for _, id := range members {
+			component[id] = i + 1
+		}
+	}
+	for id, leaf := range r.Leaves {
+		for target, info := range leaf.Dependencies {
+			info.IsCyclic = target != id && component[id] != 0 && component[id] == component[target]
+			info.IsPointingUpwards = false
+			leaf.Dependencies[target] = info
+		}
+	}
+}
+
+func (r *ProjectReport) recomputeContainedLeaves(node *ProjectNode) {
+	if node.IsLeaf() {
+		node.ContainedLeaves = []string{*node.LeafID}
+		return
+	}
+	node.ContainedLeaves = []string{}
+	for _, child := range node.Children {
+		r.recomputeContainedLeaves(child)
+		node.ContainedLeaves = append(node.ContainedLeaves, child.ContainedLeaves...)
+	}
+}
+
+// recomputeLevels sets the levels of the children of node and of all nodes
+// below them.
+func (r *ProjectReport) recomputeLevels(node *ProjectNode) {
+	childOf := make(map[string]int)
+	for i, child := range node.Children {
+		r.recomputeLevels(child)
+		for _, id := range child.ContainedLeaves {
+			childOf[id] = i
+		}
+	}
+
+	successors := make([]map[int]bool, len(node.Children))
+	incoming := make([]int, len(node.Children))
+	for i, child := range node.Children {
+		successors[i] = make(map[int]bool)
+		for _, id := range child.ContainedLeaves {
+			leaf, exists := r.Leaves[id]
+			if !exists {
+				continue
+			}
+			for target, info := range leaf.Dependencies {
+				if j, ok := childOf[target]; ok && j != i {
+					successors[i][j] = true
+					incoming[j] += info.Weight
+				}
+			}
+		}
+	}
+	breakCycles(successors, incoming)
+
+	levels := make([]int, len(node.Children))
+	for i := range levels {
+		levels[i] = -1
+	}
+	var level func(i int) int
+	level = func(i int) int {
+		if levels[i] == -1 {
+			levels[i] = 0
+			for j := range successors[i] {
+				levels[i] = max(levels[i], level(j)+1)
+			}
+		}
+		return levels[i]
+	}
+	for i, child := range node.Children {
+		child.Level = level(i)
+	}
+}
+
+// breakCycles removes edges from the graph until it is acyclic. In every
+// round it takes one cycle per strongly connected component and cuts the
+// edge pointing to the node of the cycle with the least incoming weight.
+func breakCycles(successors []map[int]bool, incoming []int) {
+	for {
+		graph := make([][]int, len(successors))
+		for i, targets := range successors {
+			for j := range targets {
+				graph[i] = append(graph[i], j)
+			}
+			sort.Ints(graph[i])
+		}
+		removed := false
+		for _, component := range tarjan(graph) {
+			if len(component) < 2 {
+				continue
+			}
+			cycle := findCycle(graph, component)
+			cut := 0
+			for i, node := range cycle {
+				if incoming[node] < incoming[cycle[cut]] {
+					cut = i
+				}
+			}
+			source := cycle[(cut+len(cycle)-1)%len(cycle)]
+			delete(successors[source], cycle[cut])
+			removed = true
+		}
+		if !removed {
+			return
+		}
+	}
+}
+
+// findCycle returns the nodes of a cycle within component, starting with its
+// smallest node, in the order they are traversed.
+func findCycle(graph [][]int, component []int) []int {
+	members := make(map[int]bool, len(component))
+	start := component[0]
+	for _, node := range component {
+		members[node] = true
+		start = min(start, node)
+	}
+	position := map[int]int{}
+	var path []int
+	var search func(node int) []int
+	search = func(node int) []int {
+		if at, onPath := position[node]; onPath {
+			return path[at:]
+		}
+		position[node] = len(path)
+		path = append(path, node)
+		for _, successor := range graph[node] {
+			if members[successor] {
+				if cycle := search(successor); cycle != nil {
+					return cycle
+				}
+			}
+		}
+		// Not reachable inside a strongly connected component, but keeps the
+		// search correct for arbitrary input.
+		delete(position, node)
+		path = path[:len(path)-1]
+		return nil
+	}
+	return search(start)
+}
+
+// recomputeDependencies sets the containedInternalDependencies of node and the
+// nodes below it. Leaf nodes copy the dependencies of their leaf and compare
+// the levels of source and target below their lowest common ancestor to set
+// isPointingUpwards; namespaces sum up the dependencies of their children.
+func (r *ProjectReport) recomputeDependencies(node *ProjectNode, paths map[string][]*ProjectNode) {
+	node.ContainedInternalDependencies = map[string]EdgeInfo{}
+	if node.IsLeaf() {
+		leaf, exists := r.Leaves[*node.LeafID]
+		if !exists {
+			return
+		}
+		for target, info := range leaf.Dependencies {
+			if targetPath, ok := paths[target]; ok {
+				sourceSide, targetSide := divergingNodes(paths[*node.LeafID], targetPath)
+				info.IsPointingUpwards = sourceSide.Level <= targetSide.Level
+			}
+			node.ContainedInternalDependencies[target] = info
+		}
+		return
+	}
+
+	types := make(map[string][]string)
+	for _, child := range node.Children {
+		r.recomputeDependencies(child, paths)
+		for _, target := range sortedKeys(child.ContainedInternalDependencies) {
+			info := child.ContainedInternalDependencies[target]
+			sum, exists := node.ContainedInternalDependencies[target]
+			if !exists {
+				node.ContainedInternalDependencies[target] = info
+				types[target] = []string{info.Type}
+				continue
+			}
+			sum.IsCyclic = sum.IsCyclic || info.IsCyclic
+			sum.IsPointingUpwards = sum.IsPointingUpwards || info.IsPointingUpwards
+			sum.Weight += info.Weight
+			if !slices.Contains(types[target], info.Type) {
+				types[target] = append(types[target], info.Type)
+				sum.Type = strings.Join(types[target], ",")
+			}
+			node.ContainedInternalDependencies[target] = sum
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package cgjson
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestRecomputeReproducesPipelineOutput(t *testing.T) {
+	// given
+	expected, err := ReadFile(exportedFiles[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	report, _ := ReadFile(exportedFiles[0])
+	for node := range report.Nodes() {
+		node.Level = 0
+		node.ContainedLeaves = nil
+		node.ContainedInternalDependencies = nil
+	}
+	for _, leaf := range report.Leaves {
+		for target, info := range leaf.Dependencies {
+			info.IsCyclic = false
+			leaf.Dependencies[target] = info
+		}
+	}
+
+	// when
+	report.Recompute()
+
+	// then
+	if !reflect.DeepEqual(report, expected) {
+		t.Error("recomputed report differs from the pipeline output")
+	}
+}
+
+func TestRecomputeLevelsAndFlags(t *testing.T) {
+	// given
+	expected := readLayered(t)
+	report := readLayered(t)
+	for node := range report.Nodes() {
+		node.Level = 0
+		node.ContainedInternalDependencies = nil
+	}
+	for _, leaf := range report.Leaves {
+		for target, info := range leaf.Dependencies {
+			info.IsCyclic = false
+			leaf.Dependencies[target] = info
+		}
+	}
+
+	// when
+	report.Recompute()
+
+	// then
+	index := NewIndex(report)
+	domain, _ := index.Node("app.domain")
+	adapter, _ := index.Node("app.adapter")
+	if domain.Level != 0 || adapter.Level != 1 {
+		t.Errorf("got levels domain %d, adapter %d", domain.Level, adapter.Level)
+	}
+	model, _ := index.LeafNode("app.domain.Model")
+	repository, _ := index.LeafNode("app.domain.Repository")
+	if model.ContainedInternalDependencies["app.domain.Repository"].IsPointingUpwards == repository.ContainedInternalDependencies["app.domain.Model"].IsPointingUpwards {
+		t.Error("expected exactly one edge of the cycle to point upwards")
+	}
+	if !report.Leaves["app.adapter.Db"].Dependencies["app.domain.Model"].IsCyclic || report.Leaves["app.adapter.Http"].Dependencies["app.adapter.Db"].IsCyclic {
+		t.Error("unexpected isCyclic flags")
+	}
+	if !reflect.DeepEqual(report, expected) {
+		t.Error("recomputed report differs from the fixture")
+	}
+}
+
+func TestStronglyConnectedComponents(t *testing.T) {
+	// given
+	successors := map[string][]string{
+		"a": {"b"},
+		"b": {"c"},
+		"c": {"a", "d"},
+		"d": {"e"},
+		"e": {"d"},
+		"f": {"a"},
+	}
+
+	// when
+	components := StronglyConnectedComponents(successors)
+
+	// then
+	expected := [][]string{{"a", "b", "c"}, {"d", "e"}}
+	if !reflect.DeepEqual(components, expected) {
+		t.Errorf("got %v, want %v", components, expected)
+	}
+}
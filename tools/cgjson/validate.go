@@ -0,0 +1,291 @@
+package cgjson
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+// Problem is an inconsistency found by Validate. Location points into the
+// file, e.g. projectTreeRoots[0].children[2].containedLeaves[1] or
+// leaves["a.B"].dependencies["a.C"].
+type Problem struct {
+	Location string `json:"location"`
+	Message  string `json:"message"`
+}
+
+func (p Problem) String() string {
+	return p.Location + ": " + p.Message
+}
+
+// Validate checks report for internal consistency:
+//   - leaves are keyed by their id and every dependency names an existing leaf
+//   - every leaf appears exactly once in the project tree and every leafId in
+//     the tree names an existing leaf
+//   - containedLeaves of every node lists exactly the leaves below it
+//   - containedInternalDependencies only name existing leaves, and those of a
+//     leaf node are the dependencies of its leaf
+//   - isCyclic is only set on dependencies within a strongly connected
+//     component, and always on those that are part of a cycle of at most
+//     maxFoundCycle dependencies, which the analysis finds in any graph
+//   - levels are non-negative, and the isPointingUpwards flags of the leaf
+//     nodes agree with the levels of the nodes they compare (see DOMAIN.md)
+//
+// The problems are grouped by check and returned in a deterministic order.
+func Validate(report *ProjectReport) []Problem {
+	validator := &validator{
+		report:    report,
+		locations: make(map[*ProjectNode]string),
+		ancestors: make(map[*ProjectNode][]*ProjectNode),
+		leafNodes: make(map[string]*ProjectNode),
+	}
+	for i, root := range report.ProjectTreeRoots {
+		validator.checkNode(root, "projectTreeRoots["+strconv.Itoa(i)+"]", nil)
+	}
+	validator.checkLeaves()
+	validator.checkLeafNodes()
+	validator.checkCycles()
+	validator.checkLevels()
+	return validator.problems
+}
+
+type validator struct {
+	report    *ProjectReport
+	problems  []Problem
+	locations map[*ProjectNode]string
+	ancestors map[*ProjectNode][]*ProjectNode
+	leafNodes map[string]*ProjectNode
+}
+
+func (v *validator) add(location, format string, args ...any) {
+	v.problems = append(v.problems, Problem{Location: location, Message: fmt.Sprintf(format, args...)})
+}
+
+// checkNode validates node and its subtree and returns the ids of all leaves
+// below it.
+func (v *validator) checkNode(node *ProjectNode, location string, ancestors []*ProjectNode) map[string]bool {
+	v.locations[node] = location
+	v.ancestors[node] = ancestors
+	if node.Level < 0 {
+		v.add(location+".level", "level %d is negative", node.Level)
+	}
+
+	leaves := make(map[string]bool)
+	if node.IsLeaf() {
+		id := *node.LeafID
+		if first, exists := v.leafNodes[id]; exists {
+			v.add(location+".leafId", "leaf %q already appears at %s", id, v.locations[first])
+		} else {
+			v.leafNodes[id] = node
+		}
+		if _, exists := v.report.Leaves[id]; !exists {
+			v.add(location+".leafId", "leaf %q does not exist in leaves", id)
+		}
+		if len(node.Children) > 0 {
+			v.add(location+".children", "leaf %q must not have children", id)
+		}
+		leaves[id] = true
+	}
+
+	childAncestors := append(append([]*ProjectNode{}, ancestors...), node)
+	for i, child := range node.Children {
+		for id := range v.checkNode(child, location+".children["+strconv.Itoa(i)+"]", childAncestors) {
+			leaves[id] = true
+		}
+	}
+
+	listed := make(map[string]bool, len(node.ContainedLeaves))
+	for i, id := range node.ContainedLeaves {
+		entryLocation := location + ".containedLeaves[" + strconv.Itoa(i) + "]"
+		switch {
+		case listed[id]:
+			v.add(entryLocation, "leaf %q is listed twice", id)
+		case !leaves[id]:
+			if _, exists := v.report.Leaves[id]; !exists {
+				v.add(entryLocation, "leaf %q does not exist in leaves", id)
+			} else {
+				v.add(entryLocation, "leaf %q is not part of this subtree", id)
+			}
+		}
+		listed[id] = true
+	}
+	for _, id := range sortedKeys(leaves) {
+		if !listed[id] {
+			v.add(location+".containedLeaves", "leaf %q of this subtree is missing", id)
+		}
+	}
+
+	for _, target := range sortedKeys(node.ContainedInternalDependencies) {
+		if _, exists := v.report.Leaves[target]; !exists {
+			v.add(location+".containedInternalDependencies["+strconv.Quote(target)+"]", "leaf %q does not exist in leaves", target)
+		}
+	}
+	return leaves
+}
+
+func (v *validator) checkLeaves() {
+	for _, id := range v.report.LeafIDs() {
+		leaf := v.report.Leaves[id]
+		location := "leaves[" + strconv.Quote(id) + "]"
+		if leaf.ID != id {
+			v.add(location+".id", "id %q does not match its key", leaf.ID)
+		}
+		if _, exists := v.leafNodes[id]; !exists {
+			v.add(location, "leaf does not appear in projectTreeRoots")
+		}
+		for _, target := range sortedKeys(leaf.Dependencies) {
+			if _, exists := v.report.Leaves[target]; !exists {
+				v.add(location+".dependencies["+strconv.Quote(target)+"]", "leaf %q does not exist in leaves", target)
+			}
+			if leaf.Dependencies[target].Weight < 1 {
+				v.add(location+".dependencies["+strconv.Quote(target)+"].weight", "weight %d is not positive", leaf.Dependencies[target].Weight)
+			}
+		}
+	}
+}
+
+// checkLeafNodes compares the containedInternalDependencies of every leaf node
+// with the dependencies of its leaf, which they copy apart from
+// isPointingUpwards.
+func (v *validator) checkLeafNodes() {
+	for _, id := range sortedKeys(v.leafNodes) {
+		leaf, exists := v.report.Leaves[id]
+		if !exists {
+			continue
+		}
+		node := v.leafNodes[id]
+		location := v.locations[node] + ".containedInternalDependencies"
+		for _, target := range sortedKeys(leaf.Dependencies) {
+			_, known := v.report.Leaves[target]
+			if _, exists := node.ContainedInternalDependencies[target]; known && !exists {
+				v.add(location, "dependency of leaf %q on %q is missing", id, target)
+			}
+		}
+		for _, target := range sortedKeys(node.ContainedInternalDependencies) {
+			expected, exists := leaf.Dependencies[target]
+			actual := node.ContainedInternalDependencies[target]
+			actual.IsPointingUpwards = expected.IsPointingUpwards
+			switch {
+			case !exists:
+				v.add(location+"["+strconv.Quote(target)+"]", "leaf %q has no dependency on %q", id, target)
+			case actual != expected:
+				v.add(location+"["+strconv.Quote(target)+"]", "differs from leaves[%q].dependencies[%q]", id, target)
+			}
+		}
+	}
+}
+
+// maxFoundCycle is the length of the longest cycles the cycle detection of
+// the analysis searches in graphs of every size. In smaller graphs it searches
+// longer ones, so dependencies that are only part of longer cycles may or may
+// not be marked cyclic.
+const maxFoundCycle = 4
+
+// checkCycles compares the isCyclic flags of the leaf dependencies with the
+// strongly connected components of the leaf graph.
+func (v *validator) checkCycles() {
+	successors := make(map[string][]string)
+	for _, id := range v.report.LeafIDs() {
+		for _, target := range sortedKeys(v.report.Leaves[id].Dependencies) {
+			if _, exists := v.report.Leaves[target]; exists && target != id {
+				successors[id] = append(successors[id], target)
+			}
+		}
+	}
+	component := make(map[string]int)
+	for i, members := range StronglyConnectedComponents(successors) {
+		for _, id := range members {
+			component[id] = i + 1
+		}
+	}
+
+	for _, id := range v.report.LeafIDs() {
+		leaf := v.report.Leaves[id]
+		for _, target := range sortedKeys(leaf.Dependencies) {
+			if _, exists := v.report.Leaves[target]; !exists {
+				continue
+			}
+			location := "leaves[" + strconv.Quote(id) + "].dependencies[" + strconv.Quote(target) + "].isCyclic"
+			inComponent := target != id && component[id] != 0 && component[id] == component[target]
+			switch {
+			case leaf.Dependencies[target].IsCyclic && !inComponent:
+				v.add(location, "is true, but %q is not reachable from %q", id, target)
+			case !leaf.Dependencies[target].IsCyclic && inComponent:
+				if length := pathLength(successors, target, id, maxFoundCycle-1); length > 0 {
+					v.add(location, "is false, but it is part of a cycle of %d dependencies", length+1)
+				}
+			}
+		}
+	}
+}
+
+// pathLength returns the number of edges of the shortest path from source to
+// target in the graph given by successors, or 0 if it is longer than limit.
+func pathLength(successors map[string][]string, source, target string, limit int) int {
+	visited := map[string]bool{source: true}
+	frontier := []string{source}
+	for length := 1; length <= limit; length++ {
+		var next []string
+		for _, id := range frontier {
+			for _, successor := range successors[id] {
+				if successor == target {
+					return length
+				}
+				if !visited[successor] {
+					visited[successor] = true
+					next = append(next, successor)
+				}
+			}
+		}
+		frontier = next
+	}
+	return 0
+}
+
+// checkLevels compares the isPointingUpwards flag of every leaf node
+// dependency with the levels of the two nodes below the lowest common ancestor
+// of source and target. The dependency points upwards exactly if the target's
+// node has the same or a higher level than the source's node.
+func (v *validator) checkLevels() {
+	for _, id := range sortedKeys(v.leafNodes) {
+		source := v.leafNodes[id]
+		for _, target := range sortedKeys(source.ContainedInternalDependencies) {
+			edge := source.ContainedInternalDependencies[target]
+			targetNode, exists := v.leafNodes[target]
+			if !exists || targetNode == source {
+				continue
+			}
+			sourceSide, targetSide := divergingNodes(v.pathTo(source), v.pathTo(targetNode))
+			expected := targetSide.Level >= sourceSide.Level
+			if edge.IsPointingUpwards != expected {
+				v.add(v.locations[source]+".containedInternalDependencies["+strconv.Quote(target)+"].isPointingUpwards",
+					"is %t, but %s has level %d and %s has level %d (leaf %q)",
+					edge.IsPointingUpwards, v.locations[sourceSide], sourceSide.Level, v.locations[targetSide], targetSide.Level, id)
+			}
+		}
+	}
+}
+
+// pathTo returns the nodes from the root down to node.
+func (v *validator) pathTo(node *ProjectNode) []*ProjectNode {
+	return append(append([]*ProjectNode{}, v.ancestors[node]...), node)
+}
+
+// divergingNodes returns the first nodes of the two root-to-node paths that
+// differ, i.e. the children of the lowest common ancestor (or the roots).
+func divergingNodes(sourcePath, targetPath []*ProjectNode) (*ProjectNode, *ProjectNode) {
+	i := 0
+	for i < len(sourcePath)-1 && i < len(targetPath)-1 && sourcePath[i] == targetPath[i] {
+		i++
+	}
+	return sourcePath[i], targetPath[i]
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,192 @@
+package cgjson
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestValidateAcceptsExportedFiles(t *testing.T) {
+	for _, path := range exportedFiles {
+		// given
+		report, err := ReadFile(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// when
+		problems := Validate(report)
+
+		// then
+		if len(problems) > 0 {
+			t.Errorf("%s: unexpected problems %v", path, problems)
+		}
+	}
+}
+
+func TestValidateReportsUnknownDependencyTarget(t *testing.T) {
+	// given
+	report := readLayered(t)
+	report.Leaves["app.adapter.Http"].Dependencies["app.adapter.Gone"] = EdgeInfo{Weight: 1, Type: "usage"}
+
+	// when
+	problems := Validate(report)
+
+	// then
+	expected := []Problem{{
+		Location: `leaves["app.adapter.Http"].dependencies["app.adapter.Gone"]`,
+		Message:  `leaf "app.adapter.Gone" does not exist in leaves`,
+	}}
+	if !reflect.DeepEqual(problems, expected) {
+		t.Errorf("got %v", problems)
+	}
+}
+
+func TestValidateReportsDuplicateLeafInTree(t *testing.T) {
+	// given
+	report := readLayered(t)
+	adapter := report.ProjectTreeRoots[0].Children[1]
+	duplicate := *adapter.Children[0]
+	adapter.Children = append(adapter.Children, &duplicate)
+
+	// when
+	problems := Validate(report)
+
+	// then
+	expected := Problem{
+		Location: "projectTreeRoots[0].children[1].children[2].leafId",
+		Message:  `leaf "app.adapter.Db" already appears at projectTreeRoots[0].children[1].children[0]`,
+	}
+	if len(problems) == 0 || problems[0] != expected {
+		t.Errorf("got %v", problems)
+	}
+}
+
+func TestValidateReportsInconsistentContainedLeaves(t *testing.T) {
+	// given
+	report := readLayered(t)
+	domain := report.ProjectTreeRoots[0].Children[0]
+	domain.ContainedLeaves = []string{"app.domain.Model", "app.adapter.Db", "app.domain.Missing"}
+
+	// when
+	problems := Validate(report)
+
+	// then
+	expected := []Problem{
+		{Location: "projectTreeRoots[0].children[0].containedLeaves[1]", Message: `leaf "app.adapter.Db" is not part of this subtree`},
+		{Location: "projectTreeRoots[0].children[0].containedLeaves[2]", Message: `leaf "app.domain.Missing" does not exist in leaves`},
+		{Location: "projectTreeRoots[0].children[0].containedLeaves", Message: `leaf "app.domain.Repository" of this subtree is missing`},
+	}
+	if !reflect.DeepEqual(problems, expected) {
+		t.Errorf("got %v", problems)
+	}
+}
+
+func TestValidateReportsLeafMissingFromTree(t *testing.T) {
+	// given
+	report := readLayered(t)
+	report.Leaves["app.Orphan"] = &LeafInformation{ID: "app.Orphan", Name: "Orphan"}
+
+	// when
+	problems := Validate(report)
+
+	// then
+	expected := []Problem{{Location: `leaves["app.Orphan"]`, Message: "leaf does not appear in projectTreeRoots"}}
+	if !reflect.DeepEqual(problems, expected) {
+		t.Errorf("got %v", problems)
+	}
+}
+
+func TestValidateReportsLevelsInconsistentWithUpwardFlags(t *testing.T) {
+	// given
+	report := readLayered(t)
+	report.ProjectTreeRoots[0].Children[1].Level = -1
+
+	// when
+	problems := Validate(report)
+
+	// then
+	expected := []Problem{
+		{Location: "projectTreeRoots[0].children[1].level", Message: "level -1 is negative"},
+		{
+			Location: `projectTreeRoots[0].children[1].children[0].containedInternalDependencies["app.domain.Model"].isPointingUpwards`,
+			Message:  `is false, but projectTreeRoots[0].children[1] has level -1 and projectTreeRoots[0].children[0] has level 0 (leaf "app.adapter.Db")`,
+		},
+		{
+			Location: `projectTreeRoots[0].children[1].children[0].containedInternalDependencies["app.domain.Repository"].isPointingUpwards`,
+			Message:  `is false, but projectTreeRoots[0].children[1] has level -1 and projectTreeRoots[0].children[0] has level 0 (leaf "app.adapter.Db")`,
+		},
+		{
+			Location: `projectTreeRoots[0].children[0].children[0].containedInternalDependencies["app.adapter.Db"].isPointingUpwards`,
+			Message:  `is true, but projectTreeRoots[0].children[0] has level 0 and projectTreeRoots[0].children[1] has level -1 (leaf "app.domain.Model")`,
+		},
+	}
+	if !reflect.DeepEqual(problems, expected) {
+		t.Errorf("got %v", problems)
+	}
+}
+
+func TestValidateReportsLeafNodeDependenciesDifferingFromTheLeaf(t *testing.T) {
+	// given
+	report := readLayered(t)
+	index := NewIndex(report)
+	httpNode, _ := index.LeafNode("app.adapter.Http")
+	delete(httpNode.ContainedInternalDependencies, "app.adapter.Db")
+	httpNode.ContainedInternalDependencies["app.domain.Model"] = EdgeInfo{Weight: 1, Type: "usage"}
+	repositoryNode, _ := index.LeafNode("app.domain.Repository")
+	changed := repositoryNode.ContainedInternalDependencies["app.domain.Model"]
+	changed.Weight = 5
+	repositoryNode.ContainedInternalDependencies["app.domain.Model"] = changed
+
+	// when
+	problems := Validate(report)
+
+	// then
+	expected := []Problem{
+		{Location: "projectTreeRoots[0].children[1].children[1].containedInternalDependencies", Message: `dependency of leaf "app.adapter.Http" on "app.adapter.Db" is missing`},
+		{Location: `projectTreeRoots[0].children[1].children[1].containedInternalDependencies["app.domain.Model"]`, Message: `leaf "app.adapter.Http" has no dependency on "app.domain.Model"`},
+		{Location: `projectTreeRoots[0].children[0].children[1].containedInternalDependencies["app.domain.Model"]`, Message: `differs from leaves["app.domain.Repository"].dependencies["app.domain.Model"]`},
+	}
+	if !reflect.DeepEqual(problems, expected) {
+		t.Errorf("got %v", problems)
+	}
+}
+
+func TestValidateReportsCyclicFlagsInconsistentWithTheCycles(t *testing.T) {
+	// given
+	report := readLayered(t)
+	index := NewIndex(report)
+	for _, edge := range []Edge{{Source: "app.adapter.Http", Target: "app.adapter.Db"}, {Source: "app.domain.Model", Target: "app.adapter.Db"}} {
+		info := report.Leaves[edge.Source].Dependencies[edge.Target]
+		info.IsCyclic = !info.IsCyclic
+		report.Leaves[edge.Source].Dependencies[edge.Target] = info
+		node, _ := index.LeafNode(edge.Source)
+		info.IsPointingUpwards = node.ContainedInternalDependencies[edge.Target].IsPointingUpwards
+		node.ContainedInternalDependencies[edge.Target] = info
+	}
+
+	// when
+	problems := Validate(report)
+
+	// then
+	expected := []Problem{
+		{Location: `leaves["app.adapter.Http"].dependencies["app.adapter.Db"].isCyclic`, Message: `is true, but "app.adapter.Http" is not reachable from "app.adapter.Db"`},
+		{Location: `leaves["app.domain.Model"].dependencies["app.adapter.Db"].isCyclic`, Message: "is false, but it is part of a cycle of 2 dependencies"},
+	}
+	if !reflect.DeepEqual(problems, expected) {
+		t.Errorf("got %v", problems)
+	}
+}
+
+func TestPathLengthStopsAtLimit(t *testing.T) {
+	// given
+	successors := map[string][]string{"a": {"b"}, "b": {"c", "e"}, "c": {"d"}, "d": {"e"}}
+
+	// when
+	short := pathLength(successors, "a", "e", 3)
+	long := pathLength(successors, "a", "d", 2)
+
+	// then
+	if short != 2 || long != 0 {
+		t.Errorf("got %d and %d", short, long)
+	}
+}
@@ -0,0 +1,150 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+)
+
+// Baseline lists the known violations of an analysis: the cyclic and the
+// upward-pointing dependencies. An edge that is both appears in both lists,
+// so that it becoming upward-pointing later is a new violation even if it
+// was already cyclic.
+type Baseline struct {
+	Cyclic []Entry `json:"cyclic"`
+	Upward []Entry `json:"upward"`
+}
+
+// Entry is a dependency from the leaf Source to the leaf Target.
+type Entry struct {
+	Source string `json:"source"`
+	Target string `json:"target"`
+}
+
+// Result is the outcome of comparing an analysis with a baseline. New holds
+// the violations missing from the baseline, Resolved the baseline entries that
+// are no longer violations.
+type Result struct {
+	New      Baseline `json:"new"`
+	Resolved Baseline `json:"resolved"`
+}
+
+// Len returns the number of entries.
+func (b Baseline) Len() int {
+	return len(b.Cyclic) + len(b.Upward)
+}
+
+// Record collects the violations of report, ordered by source and target.
+func Record(report *cgjson.ProjectReport) Baseline {
+	baseline := Baseline{Cyclic: []Entry{}, Upward: []Entry{}}
+	for edge := range report.Edges() {
+		entry := Entry{Source: edge.Source, Target: edge.Target}
+		if edge.IsCyclic {
+			baseline.Cyclic = append(baseline.Cyclic, entry)
+		}
+		if edge.IsPointingUpwards {
+			baseline.Upward = append(baseline.Upward, entry)
+		}
+	}
+	return baseline
+}
+
+// Check compares the violations of report with baseline.
+func Check(baseline Baseline, report *cgjson.ProjectReport) Result {
+	current := Record(report)
+	return Result{
+		New: Baseline{
+			Cyclic: missingIn(current.Cyclic, baseline.Cyclic),
+			Upward: missingIn(current.Upward, baseline.Upward),
+		},
+		Resolved: Baseline{
+			Cyclic: missingIn(baseline.Cyclic, current.Cyclic),
+			Upward: missingIn(baseline.Upward, current.Upward),
+		},
+	}
+}
+
+// Shrink returns baseline without the resolved entries. New violations are
+// never added, so the baseline can only get smaller.
+func (b Baseline) Shrink(resolved Baseline) Baseline {
+	return Baseline{
+		Cyclic: missingIn(b.Cyclic, resolved.Cyclic),
+		Upward: missingIn(b.Upward, resolved.Upward),
+	}
+}
+
+// missingIn returns the entries of entries that are not in other, sorted.
+func missingIn(entries, other []Entry) []Entry {
+	known := make(map[Entry]bool, len(other))
+	for _, entry := range other {
+		known[entry] = true
+	}
+	missing := []Entry{}
+	for _, entry := range entries {
+		if !known[entry] {
+			missing = append(missing, entry)
+		}
+	}
+	sort.Slice(missing, func(i, j int) bool {
+		if missing[i].Source != missing[j].Source {
+			return missing[i].Source < missing[j].Source
+		}
+		return missing[i].Target < missing[j].Target
+	})
+	return missing
+}
+
+// ReadBaseline reads the baseline file at path.
+func ReadBaseline(path string) (Baseline, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return Baseline{}, err
+	}
+	var baseline Baseline
+	if err := json.Unmarshal(data, &baseline); err != nil {
+		return Baseline{}, fmt.Errorf("%s: %w", path, err)
+	}
+	return baseline, nil
+}
+
+// WriteBaseline writes baseline to path as indented JSON with one entry per
+// line, so that changes to it are easy to review.
+func WriteBaseline(path string, baseline Baseline) error {
+	var buffer bytes.Buffer
+	buffer.WriteString("{\n")
+	if err := writeEntries(&buffer, "cyclic", baseline.Cyclic); err != nil {
+		return err
+	}
+	buffer.WriteString(",\n")
+	if err := writeEntries(&buffer, "upward", baseline.Upward); err != nil {
+		return err
+	}
+	buffer.WriteString("\n}\n")
+	return os.WriteFile(path, buffer.Bytes(), 0o644)
+}
+
+func writeEntries(buffer *bytes.Buffer, name string, entries []Entry) error {
+	fmt.Fprintf(buffer, "  %q: [", name)
+	// The encoder keeps <, > and & of generic types readable.
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	for i, entry := range entries {
+		if i > 0 {
+			buffer.WriteString(",")
+		}
+		buffer.WriteString("\n    ")
+		if err := encoder.Encode(entry); err != nil {
+			return err
+		}
+		buffer.Truncate(buffer.Len() - 1)
+	}
+	if len(entries) > 0 {
+		buffer.WriteString("\n  ")
+	}
+	buffer.WriteString("]")
+	return nil
+}
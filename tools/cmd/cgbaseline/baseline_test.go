@@ -0,0 +1,97 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson/cgjsontest"
+)
+
+func TestRecordCollectsCyclicAndUpwardEdges(t *testing.T) {
+	// given
+	report := cgjsontest.Layered(t)
+
+	// when
+	baseline := Record(report)
+
+	// then
+	expected := Baseline{
+		Cyclic: []Entry{
+			{Source: "app.adapter.Db", Target: "app.domain.Model"},
+			{Source: "app.adapter.Db", Target: "app.domain.Repository"},
+			{Source: "app.domain.Model", Target: "app.adapter.Db"},
+			{Source: "app.domain.Model", Target: "app.domain.Repository"},
+			{Source: "app.domain.Repository", Target: "app.domain.Model"},
+		},
+		Upward: []Entry{
+			{Source: "app.domain.Model", Target: "app.adapter.Db"},
+			{Source: "app.domain.Repository", Target: "app.domain.Model"},
+		},
+	}
+	if !reflect.DeepEqual(baseline, expected) {
+		t.Errorf("got %+v\nwant %+v", baseline, expected)
+	}
+}
+
+func TestCheckReportsOnlyViolationsMissingFromBaseline(t *testing.T) {
+	// given
+	baseline := Record(cgjsontest.Layered(t))
+	report := cgjsontest.Layered(t)
+	httpNode, _ := cgjson.NewIndex(report).LeafNode("app.adapter.Http")
+	httpNode.ContainedInternalDependencies["app.adapter.Db"] = cgjson.EdgeInfo{Weight: 1, Type: "usage", IsPointingUpwards: true}
+
+	// when
+	result := Check(baseline, report)
+
+	// then
+	expected := Result{
+		New:      Baseline{Cyclic: []Entry{}, Upward: []Entry{{Source: "app.adapter.Http", Target: "app.adapter.Db"}}},
+		Resolved: Baseline{Cyclic: []Entry{}, Upward: []Entry{}},
+	}
+	if !reflect.DeepEqual(result, expected) {
+		t.Errorf("got %+v\nwant %+v", result, expected)
+	}
+}
+
+func TestShrinkRemovesResolvedViolations(t *testing.T) {
+	// given
+	baseline := Record(cgjsontest.Layered(t))
+	report := cgjsontest.Layered(t)
+	delete(report.Leaves["app.domain.Model"].Dependencies, "app.adapter.Db")
+	report.Recompute()
+
+	// when
+	result := Check(baseline, report)
+	shrunk := baseline.Shrink(result.Resolved)
+
+	// then
+	if result.New.Len() != 0 || result.Resolved.Len() != 4 {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if shrunk.Len() != 3 || !reflect.DeepEqual(shrunk.Upward, []Entry{{Source: "app.domain.Repository", Target: "app.domain.Model"}}) {
+		t.Errorf("unexpected baseline %+v", shrunk)
+	}
+}
+
+func TestWriteAndReadBaseline(t *testing.T) {
+	// given
+	path := filepath.Join(t.TempDir(), "baseline.json")
+	baseline := Baseline{
+		Cyclic: []Entry{{Source: "a.List<T>", Target: "a.Node<T>"}},
+		Upward: []Entry{},
+	}
+
+	// when
+	err := WriteBaseline(path, baseline)
+	read, readErr := ReadBaseline(path)
+
+	// then
+	if err != nil || readErr != nil {
+		t.Fatal(err, readErr)
+	}
+	if !reflect.DeepEqual(read, baseline) {
+		t.Errorf("got %+v\nwant %+v", read, baseline)
+	}
+}
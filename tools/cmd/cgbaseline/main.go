@@ -0,0 +1,109 @@
+// Command cgbaseline lets legacy projects adopt DependaCharta as a CI gate by
+// failing only on cyclic and upward-pointing dependencies that are not yet
+// known.
+//
+// Usage:
+//
+//	cgbaseline -update baseline.json analysis.cg.json
+//	cgbaseline [-json] [-no-shrink] baseline.json analysis.cg.json
+//
+// With -update the violations of the analysis are recorded in the baseline
+// file. Without it, the analysis is compared with the baseline: the exit code
+// is 1 if it contains violations that are not in the baseline. Baseline
+// entries that are no longer violations are removed from the file unless
+// -no-shrink is given, so the baseline ratchets down as violations get fixed.
+// Errors exit with 2.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+)
+
+func main() {
+	update := flag.Bool("update", false, "record the violations of the analysis as the new baseline")
+	noShrink := flag.Bool("no-shrink", false, "do not remove resolved violations from the baseline file")
+	asJSON := flag.Bool("json", false, "print the new and resolved violations as JSON")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: cgbaseline [-update] [-json] [-no-shrink] baseline.json analysis.cg.json\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	baselinePath := flag.Arg(0)
+
+	report, err := cgjson.ReadFile(flag.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if *update {
+		recorded := Record(report)
+		if err := WriteBaseline(baselinePath, recorded); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		fmt.Printf("Recorded %d cyclic and %d upward dependencies in %s.\n", len(recorded.Cyclic), len(recorded.Upward), baselinePath)
+		return
+	}
+
+	baseline, err := ReadBaseline(baselinePath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		fmt.Fprintln(os.Stderr, "Run with -update to create the baseline.")
+		os.Exit(2)
+	}
+	result := Check(baseline, report)
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(result); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	} else {
+		writeText(os.Stdout, result)
+	}
+
+	if result.Resolved.Len() > 0 && !*noShrink {
+		if err := WriteBaseline(baselinePath, baseline.Shrink(result.Resolved)); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		fmt.Fprintf(os.Stderr, "Removed %d resolved violations from %s.\n", result.Resolved.Len(), baselinePath)
+	}
+
+	if result.New.Len() > 0 {
+		os.Exit(1)
+	}
+}
+
+func writeText(w io.Writer, result Result) {
+	fmt.Fprintf(w, "%d new violations (%d cyclic, %d upward), %d resolved.\n",
+		result.New.Len(), len(result.New.Cyclic), len(result.New.Upward), result.Resolved.Len())
+	writeList(w, "New cyclic dependencies", result.New.Cyclic)
+	writeList(w, "New upward dependencies", result.New.Upward)
+	writeList(w, "Resolved cyclic dependencies", result.Resolved.Cyclic)
+	writeList(w, "Resolved upward dependencies", result.Resolved.Upward)
+}
+
+func writeList(w io.Writer, title string, entries []Entry) {
+	if len(entries) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%s (%d):\n", title, len(entries))
+	for _, entry := range entries {
+		fmt.Fprintf(w, "  %s -> %s\n", entry.Source, entry.Target)
+	}
+}
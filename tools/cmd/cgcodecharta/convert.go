@@ -0,0 +1,172 @@
+package main
+
+import "github.com/MaibornWolff/DependaCharta/tools/cgjson"
+
+// The types below describe the cc.json format of CodeCharta, see
+// https://codecharta.com/docs/general-introduction/data-format.
+
+// apiVersion is the cc.json version written.
+const apiVersion = "1.3"
+
+type Project struct {
+	ProjectName          string                         `json:"projectName"`
+	APIVersion           string                         `json:"apiVersion"`
+	Nodes                []*Node                        `json:"nodes"`
+	Edges                []Edge                         `json:"edges"`
+	AttributeTypes       AttributeTypes                 `json:"attributeTypes"`
+	AttributeDescriptors map[string]AttributeDescriptor `json:"attributeDescriptors"`
+	Blacklist            []any                          `json:"blacklist"`
+}
+
+type Node struct {
+	Name       string             `json:"name"`
+	Type       string             `json:"type"`
+	Attributes map[string]float64 `json:"attributes"`
+	Children   []*Node            `json:"children,omitempty"`
+}
+
+type Edge struct {
+	FromNodeName string             `json:"fromNodeName"`
+	ToNodeName   string             `json:"toNodeName"`
+	Attributes   map[string]float64 `json:"attributes"`
+}
+
+type AttributeTypes struct {
+	Nodes map[string]string `json:"nodes"`
+	Edges map[string]string `json:"edges"`
+}
+
+type AttributeDescriptor struct {
+	Title         string `json:"title"`
+	Description   string `json:"description"`
+	HintLowValue  string `json:"hintLowValue"`
+	HintHighValue string `json:"hintHighValue"`
+	Link          string `json:"link"`
+	// Direction is -1 if lower values are better and 1 if higher values
+	// are better.
+	Direction int `json:"direction"`
+}
+
+// The attributes of the leaves and dependencies. Level has no descriptor, as
+// neither low nor high levels are better.
+const (
+	FanIn        = "fan_in"
+	FanOut       = "fan_out"
+	CyclicEdges  = "cyclic_edges"
+	UpwardEdges  = "upward_edges"
+	Level        = "level"
+	Weight       = "weight"
+	IsCyclic     = "is_cyclic"
+	IsPointingUp = "is_pointing_upwards"
+)
+
+var descriptors = map[string]AttributeDescriptor{
+	FanIn:        {Title: "Fan-in", Description: "Number of leaves depending on this leaf", Direction: -1},
+	FanOut:       {Title: "Fan-out", Description: "Number of leaves this leaf depends on", Direction: -1},
+	CyclicEdges:  {Title: "Cyclic dependencies", Description: "Number of dependencies of this leaf that are part of a cycle", Direction: -1},
+	UpwardEdges:  {Title: "Upward dependencies", Description: "Number of dependencies of this leaf that point upwards in the architecture", Direction: -1},
+	Weight:       {Title: "Weight", Description: "Number of usages the dependency consists of", Direction: -1},
+	IsCyclic:     {Title: "Cyclic", Description: "1 if the dependency is part of a cycle", Direction: -1},
+	IsPointingUp: {Title: "Pointing upwards", Description: "1 if the dependency points upwards in the architecture", Direction: -1},
+}
+
+// Convert maps the project tree of report to CodeCharta folders and files,
+// with the leaves as files. Every leaf gets its fan-in, fan-out, the number of
+// its cyclic and upward-pointing dependencies and its level as attributes;
+// attributes already stored in the analysis, e.g. by cgmetrics, are kept.
+// Every dependency becomes an edge with its weight and whether it is cyclic or
+// points upwards.
+func Convert(report *cgjson.ProjectReport, projectName string) Project {
+	index := cgjson.NewIndex(report)
+	fanIn, fanOut, cyclic, upward := map[string]int{}, map[string]int{}, map[string]int{}, map[string]int{}
+	var edges []cgjson.Edge
+	for edge := range report.Edges() {
+		edges = append(edges, edge)
+		fanIn[edge.Target]++
+		fanOut[edge.Source]++
+		if edge.IsCyclic {
+			cyclic[edge.Source]++
+		}
+		if edge.IsPointingUpwards {
+			upward[edge.Source]++
+		}
+	}
+
+	var convert func(node *cgjson.ProjectNode) *Node
+	convert = func(node *cgjson.ProjectNode) *Node {
+		result := &Node{Name: node.Name, Attributes: map[string]float64{}}
+		for name, value := range node.Attributes {
+			result.Attributes[name] = value
+		}
+		if node.IsLeaf() && len(node.Children) == 0 {
+			id := *node.LeafID
+			result.Type = "File"
+			result.Attributes[FanIn] = float64(fanIn[id])
+			result.Attributes[FanOut] = float64(fanOut[id])
+			result.Attributes[CyclicEdges] = float64(cyclic[id])
+			result.Attributes[UpwardEdges] = float64(upward[id])
+			result.Attributes[Level] = float64(node.Level)
+			return result
+		}
+		result.Type = "Folder"
+		result.Children = []*Node{}
+		for _, child := range node.Children {
+			result.Children = append(result.Children, convert(child))
+		}
+		return result
+	}
+	root := &Node{Name: "root", Type: "Folder", Attributes: map[string]float64{}, Children: []*Node{}}
+	for _, node := range report.ProjectTreeRoots {
+		root.Children = append(root.Children, convert(node))
+	}
+
+	project := Project{
+		ProjectName: projectName,
+		APIVersion:  apiVersion,
+		Nodes:       []*Node{root},
+		Edges:       []Edge{},
+		AttributeTypes: AttributeTypes{
+			Nodes: map[string]string{FanIn: "absolute", FanOut: "absolute", CyclicEdges: "absolute", UpwardEdges: "absolute", Level: "absolute"},
+			Edges: map[string]string{Weight: "absolute", IsCyclic: "absolute", IsPointingUp: "absolute"},
+		},
+		AttributeDescriptors: map[string]AttributeDescriptor{},
+		Blacklist:            []any{},
+	}
+	for name, descriptor := range descriptors {
+		descriptor.Link = "https://github.com/MaibornWolff/DependaCharta"
+		project.AttributeDescriptors[name] = descriptor
+	}
+	for _, edge := range edges {
+		project.Edges = append(project.Edges, Edge{
+			FromNodeName: nodeName(index, edge.Source),
+			ToNodeName:   nodeName(index, edge.Target),
+			Attributes: map[string]float64{
+				Weight:       float64(edge.Weight),
+				IsCyclic:     indicator(edge.IsCyclic),
+				IsPointingUp: indicator(edge.IsPointingUpwards),
+			},
+		})
+	}
+	return project
+}
+
+// nodeName returns the path of a leaf in the cc.json tree, e.g.
+// "/root/app/domain/Model".
+func nodeName(index *cgjson.Index, id string) string {
+	node, ok := index.LeafNode(id)
+	if !ok {
+		return "/root/" + id
+	}
+	path := "/" + node.Name
+	for _, ancestor := range index.Ancestors(node) {
+		path = "/" + ancestor.Name + path
+	}
+	return "/root" + path
+}
+
+func indicator(set bool) float64 {
+	if set {
+		return 1
+	}
+	return 0
+}
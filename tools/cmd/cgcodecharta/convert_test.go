@@ -0,0 +1,136 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson/cgjsontest"
+)
+
+func TestConvertMapsNamespacesToFoldersAndLeavesToFiles(t *testing.T) {
+	// given
+	report := cgjsontest.Layered(t)
+
+	// when
+	project := Convert(report, "layered")
+
+	// then
+	root := project.Nodes[0]
+	if project.ProjectName != "layered" || project.APIVersion != "1.3" || root.Name != "root" || root.Type != "Folder" {
+		t.Fatalf("got %+v", project)
+	}
+	domain := root.Children[0].Children[0]
+	if domain.Name != "domain" || domain.Type != "Folder" || len(domain.Children) != 2 {
+		t.Fatalf("got %+v", domain)
+	}
+	model := domain.Children[0]
+	expected := map[string]float64{FanIn: 2, FanOut: 2, CyclicEdges: 2, UpwardEdges: 1, Level: 1}
+	if model.Name != "Model" || model.Type != "File" || model.Children != nil || !reflect.DeepEqual(model.Attributes, expected) {
+		t.Errorf("got %+v", model)
+	}
+}
+
+func TestConvertMapsDependenciesToEdges(t *testing.T) {
+	// given
+	report := cgjsontest.Layered(t)
+
+	// when
+	project := Convert(report, "layered")
+
+	// then
+	if len(project.Edges) != 6 {
+		t.Fatalf("got %d edges", len(project.Edges))
+	}
+	expected := Edge{
+		FromNodeName: "/root/app/domain/Model",
+		ToNodeName:   "/root/app/adapter/Db",
+		Attributes:   map[string]float64{Weight: 2, IsCyclic: 1, IsPointingUp: 1},
+	}
+	if !reflect.DeepEqual(project.Edges[3], expected) {
+		t.Errorf("got %+v", project.Edges[3])
+	}
+}
+
+func TestConvertKeepsAttributesOfTheAnalysis(t *testing.T) {
+	// given
+	report := cgjsontest.Layered(t)
+	report.ProjectTreeRoots[0].Children[0].Attributes = map[string]float64{"instability": 0.5}
+
+	// when
+	project := Convert(report, "layered")
+
+	// then
+	if attributes := project.Nodes[0].Children[0].Children[0].Attributes; !reflect.DeepEqual(attributes, map[string]float64{"instability": 0.5}) {
+		t.Errorf("got %v", attributes)
+	}
+}
+
+func TestConvertDoesNotCountSelfDependenciesAsUpward(t *testing.T) {
+	// given
+	report := cgjsontest.Layered(t)
+	report.Leaves["app.adapter.Http"].Dependencies["app.adapter.Http"] = cgjson.EdgeInfo{Weight: 1, Type: "usage"}
+	node, _ := cgjson.NewIndex(report).LeafNode("app.adapter.Http")
+	node.ContainedInternalDependencies["app.adapter.Http"] = cgjson.EdgeInfo{Weight: 1, Type: "usage", IsPointingUpwards: true}
+
+	// when
+	project := Convert(report, "layered")
+
+	// then
+	http := project.Nodes[0].Children[0].Children[1].Children[1]
+	if http.Attributes[UpwardEdges] != 0 || http.Attributes[CyclicEdges] != 0 {
+		t.Errorf("got %v", http.Attributes)
+	}
+}
+
+func TestWriteCompressesTheCompleteFile(t *testing.T) {
+	// given
+	path := filepath.Join(t.TempDir(), "analysis.cc.json.gz")
+	project := Convert(cgjsontest.Layered(t), "layered")
+
+	// when
+	err := write(path, project)
+
+	// then
+	if err != nil {
+		t.Fatal(err)
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+	reader, err := gzip.NewReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	content, err := io.ReadAll(reader)
+	if err != nil {
+		t.Fatalf("reading the compressed file to its footer: %v", err)
+	}
+	var written Project
+	if err := json.Unmarshal(content, &written); err != nil {
+		t.Fatal(err)
+	}
+	if written.ProjectName != "layered" || len(written.Edges) != len(project.Edges) {
+		t.Errorf("got %+v", written)
+	}
+}
+
+func TestWriteFailsForUnwritablePaths(t *testing.T) {
+	// given
+	path := filepath.Join(t.TempDir(), "missing", "analysis.cc.json")
+
+	// when
+	err := write(path, Convert(cgjsontest.Layered(t), "layered"))
+
+	// then
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
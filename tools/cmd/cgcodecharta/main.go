@@ -0,0 +1,68 @@
+// Command cgcodecharta converts a .cg.json file into a CodeCharta cc.json
+// file, so that dependency-debt hotspots can be viewed on the CodeCharta city
+// map.
+//
+// Usage:
+//
+//	cgcodecharta [-project name] [-o analysis.cc.json[.gz]] analysis.cg.json
+//
+// The namespaces become folders and the leaves files with the attributes
+// fan_in, fan_out, cyclic_edges, upward_edges and level. The dependencies
+// become edges with the attributes weight, is_cyclic and is_pointing_upwards.
+// An output file ending with .gz is compressed, as CodeCharta accepts both.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+)
+
+func main() {
+	projectName := flag.String("project", "", "project name (default the file name without .cg.json)")
+	output := flag.String("o", "", "write the cc.json to this file instead of stdout, compressed if it ends with .gz")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: cgcodecharta [-project name] [-o analysis.cc.json[.gz]] analysis.cg.json\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	report, err := cgjson.ReadFile(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *projectName == "" {
+		*projectName = strings.TrimSuffix(filepath.Base(flag.Arg(0)), ".cg.json")
+	}
+	if err := write(*output, Convert(report, *projectName)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+}
+
+// write writes project to path, or to stdout if path is empty.
+func write(path string, project Project) error {
+	if path == "" {
+		return encode(os.Stdout, project)
+	}
+	return cgjson.CreateFile(path, strings.HasSuffix(path, ".gz"), func(w io.Writer) error {
+		return encode(w, project)
+	})
+}
+
+func encode(w io.Writer, project Project) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(project)
+}
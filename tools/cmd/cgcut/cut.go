@@ -0,0 +1,318 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+)
+
+// Cut is a dependency suggested for removal to break cycles.
+type Cut struct {
+	Rank     int             `json:"rank"`
+	Source   string          `json:"source"`
+	Target   string          `json:"target"`
+	Weight   int             `json:"weight"`
+	EdgeType cgjson.EdgeType `json:"edgeType"`
+	// Cycles is the number of simple cycles through the dependency, as far
+	// as they were counted for its component.
+	Cycles int `json:"cycles"`
+	// Component is the 1-based index of the strongly connected component
+	// the dependency belongs to.
+	Component int `json:"component"`
+}
+
+// Component is a strongly connected component of leaves.
+type Component struct {
+	Leaves []string `json:"leaves"`
+	// Edges and Weight are the number and total weight of the cyclic
+	// dependencies inside the component.
+	Edges  int `json:"edges"`
+	Weight int `json:"weight"`
+	// Cycles is the number of simple cycles in the component. Complete is
+	// false if counting stopped at the limit.
+	Cycles   int  `json:"cycles"`
+	Complete bool `json:"complete"`
+	// CutWeight is the total weight of the suggested cuts, which break all
+	// cycles of the component.
+	CutWeight int `json:"cutWeight"`
+}
+
+// Plan is the result of Suggest.
+type Plan struct {
+	Components []Component `json:"components"`
+	Cuts       []Cut       `json:"cuts"`
+}
+
+// Suggest computes a small set of dependencies whose removal makes every
+// strongly connected component of cyclic dependencies acyclic, weighted by
+// EdgeInfo.weight so that cheap dependencies are preferred. Finding a minimum
+// feedback arc set is NP-hard, so each component is ordered with the
+// Eades-Lin-Smyth heuristic, generalized to weights, and the dependencies
+// pointing backwards in that order are cut. Cuts that turn out to be
+// unnecessary afterwards are dropped again, most expensive first.
+//
+// The cuts are ranked by the number of simple cycles they break per unit of
+// weight. Cycles are counted up to maxCycles per component.
+func Suggest(report *cgjson.ProjectReport, maxCycles int) Plan {
+	successors := map[string][]string{}
+	edges := map[string]map[string]cgjson.Edge{}
+	for edge := range report.Edges() {
+		if !edge.IsCyclic {
+			continue
+		}
+		successors[edge.Source] = append(successors[edge.Source], edge.Target)
+		if edges[edge.Source] == nil {
+			edges[edge.Source] = map[string]cgjson.Edge{}
+		}
+		edges[edge.Source][edge.Target] = edge
+	}
+
+	plan := Plan{Components: []Component{}, Cuts: []Cut{}}
+	for i, leaves := range cgjson.StronglyConnectedComponents(successors) {
+		graph := newGraph(leaves, edges)
+		component := Component{Leaves: leaves, Edges: len(graph.edges)}
+		for _, edge := range graph.edges {
+			component.Weight += edge.Weight
+		}
+
+		cut := graph.prune(graph.backwardEdges(graph.order()))
+		counts := map[cgjson.Edge]int{}
+		component.Cycles, component.Complete = graph.countCycles(maxCycles, func(cycle []cgjson.Edge) {
+			for _, edge := range cycle {
+				counts[edge]++
+			}
+		})
+		for _, edge := range cut {
+			component.CutWeight += edge.Weight
+			plan.Cuts = append(plan.Cuts, Cut{
+				Source:    edge.Source,
+				Target:    edge.Target,
+				Weight:    edge.Weight,
+				EdgeType:  edge.EdgeType(),
+				Cycles:    counts[edge],
+				Component: i + 1,
+			})
+		}
+		plan.Components = append(plan.Components, component)
+	}
+
+	sort.Slice(plan.Cuts, func(i, j int) bool {
+		a, b := plan.Cuts[i], plan.Cuts[j]
+		if a.Cycles*b.Weight != b.Cycles*a.Weight {
+			return a.Cycles*b.Weight > b.Cycles*a.Weight
+		}
+		if a.Weight != b.Weight {
+			return a.Weight < b.Weight
+		}
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		return a.Target < b.Target
+	})
+	for i := range plan.Cuts {
+		plan.Cuts[i].Rank = i + 1
+	}
+	return plan
+}
+
+// graph is a strongly connected component with its cyclic dependencies.
+type graph struct {
+	leaves     []string
+	edges      []cgjson.Edge
+	successors map[string][]cgjson.Edge
+}
+
+func newGraph(leaves []string, edges map[string]map[string]cgjson.Edge) *graph {
+	members := map[string]bool{}
+	for _, id := range leaves {
+		members[id] = true
+	}
+	g := &graph{leaves: leaves, successors: map[string][]cgjson.Edge{}}
+	for _, source := range leaves {
+		targets := make([]string, 0, len(edges[source]))
+		for target := range edges[source] {
+			if members[target] {
+				targets = append(targets, target)
+			}
+		}
+		sort.Strings(targets)
+		for _, target := range targets {
+			edge := edges[source][target]
+			g.edges = append(g.edges, edge)
+			g.successors[source] = append(g.successors[source], edge)
+		}
+	}
+	return g
+}
+
+// order arranges the leaves so that few and light dependencies point
+// backwards: sinks go to the end and sources to the front as long as there
+// are any, otherwise the leaf whose outgoing weight exceeds its incoming
+// weight the most goes to the front.
+func (g *graph) order() []string {
+	remaining := map[string]bool{}
+	outgoing, incoming := map[string]int{}, map[string]int{}
+	outWeight, inWeight := map[string]int{}, map[string]int{}
+	predecessors := map[string][]cgjson.Edge{}
+	for _, id := range g.leaves {
+		remaining[id] = true
+	}
+	for _, edge := range g.edges {
+		outgoing[edge.Source]++
+		incoming[edge.Target]++
+		outWeight[edge.Source] += edge.Weight
+		inWeight[edge.Target] += edge.Weight
+		predecessors[edge.Target] = append(predecessors[edge.Target], edge)
+	}
+	remove := func(id string) {
+		delete(remaining, id)
+		for _, edge := range g.successors[id] {
+			incoming[edge.Target]--
+			inWeight[edge.Target] -= edge.Weight
+		}
+		for _, edge := range predecessors[id] {
+			outgoing[edge.Source]--
+			outWeight[edge.Source] -= edge.Weight
+		}
+	}
+	find := func(matches func(id string) bool) (string, bool) {
+		for _, id := range g.leaves {
+			if remaining[id] && matches(id) {
+				return id, true
+			}
+		}
+		return "", false
+	}
+
+	var front, back []string
+	for len(remaining) > 0 {
+		if sink, ok := find(func(id string) bool { return outgoing[id] == 0 }); ok {
+			back = append([]string{sink}, back...)
+			remove(sink)
+			continue
+		}
+		if source, ok := find(func(id string) bool { return incoming[id] == 0 }); ok {
+			front = append(front, source)
+			remove(source)
+			continue
+		}
+		best := ""
+		for _, id := range g.leaves {
+			if remaining[id] && (best == "" || outWeight[id]-inWeight[id] > outWeight[best]-inWeight[best]) {
+				best = id
+			}
+		}
+		front = append(front, best)
+		remove(best)
+	}
+	return append(front, back...)
+}
+
+// backwardEdges returns the dependencies pointing backwards in order.
+func (g *graph) backwardEdges(order []string) []cgjson.Edge {
+	position := map[string]int{}
+	for i, id := range order {
+		position[id] = i
+	}
+	var backward []cgjson.Edge
+	for _, edge := range g.edges {
+		if position[edge.Target] < position[edge.Source] {
+			backward = append(backward, edge)
+		}
+	}
+	return backward
+}
+
+// prune restores the cut dependencies that do not close a cycle with the
+// remaining ones, trying the heaviest first, and returns the rest.
+func (g *graph) prune(cut []cgjson.Edge) []cgjson.Edge {
+	removed := map[cgjson.Edge]bool{}
+	for _, edge := range cut {
+		removed[edge] = true
+	}
+	byWeight := append([]cgjson.Edge(nil), cut...)
+	sort.SliceStable(byWeight, func(i, j int) bool { return byWeight[i].Weight > byWeight[j].Weight })
+	for _, edge := range byWeight {
+		delete(removed, edge)
+		if g.reaches(edge.Target, edge.Source, removed) {
+			removed[edge] = true
+		}
+	}
+	var kept []cgjson.Edge
+	for _, edge := range cut {
+		if removed[edge] {
+			kept = append(kept, edge)
+		}
+	}
+	return kept
+}
+
+// reaches reports whether to can be reached from from without the removed
+// dependencies.
+func (g *graph) reaches(from, to string, removed map[cgjson.Edge]bool) bool {
+	visited := map[string]bool{from: true}
+	stack := []string{from}
+	for len(stack) > 0 {
+		current := stack[len(stack)-1]
+		stack = stack[:len(stack)-1]
+		if current == to {
+			return true
+		}
+		for _, edge := range g.successors[current] {
+			if !removed[edge] && !visited[edge.Target] {
+				visited[edge.Target] = true
+				stack = append(stack, edge.Target)
+			}
+		}
+	}
+	return false
+}
+
+// countCycles calls visit with every simple cycle of the component, up to
+// limit cycles. It returns the number of cycles and whether all were found.
+// Each cycle is found once, starting from its smallest leaf. As the number of
+// paths to search can grow much faster than the number of cycles, the search
+// also stops after limit steps per leaf.
+func (g *graph) countCycles(limit int, visit func(cycle []cgjson.Edge)) (int, bool) {
+	index := map[string]int{}
+	for i, id := range g.leaves {
+		index[id] = i
+	}
+	count, steps := 0, 0
+	var path []cgjson.Edge
+	onPath := map[string]bool{}
+	var walk func(start, current string) bool
+	walk = func(start, current string) bool {
+		for _, edge := range g.successors[current] {
+			switch {
+			case edge.Target == start:
+				if count == limit {
+					return false
+				}
+				count++
+				visit(append(path, edge))
+			case index[edge.Target] > index[start] && !onPath[edge.Target]:
+				if steps == 0 {
+					return false
+				}
+				steps--
+				onPath[edge.Target] = true
+				path = append(path, edge)
+				complete := walk(start, edge.Target)
+				path = path[:len(path)-1]
+				delete(onPath, edge.Target)
+				if !complete {
+					return false
+				}
+			}
+		}
+		return true
+	}
+	for _, start := range g.leaves {
+		steps = limit
+		if !walk(start, start) {
+			return count, false
+		}
+	}
+	return count, true
+}
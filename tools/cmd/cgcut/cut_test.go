@@ -0,0 +1,151 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson/cgjsontest"
+)
+
+// cyclic builds a report from "source target weight" triples, all of them
+// cyclic dependencies.
+func cyclic(dependencies ...any) *cgjson.ProjectReport {
+	report := &cgjson.ProjectReport{Leaves: map[string]*cgjson.LeafInformation{}}
+	for i := 0; i < len(dependencies); i += 3 {
+		source, target, weight := dependencies[i].(string), dependencies[i+1].(string), dependencies[i+2].(int)
+		for _, id := range []string{source, target} {
+			if report.Leaves[id] == nil {
+				report.Leaves[id] = &cgjson.LeafInformation{ID: id, Dependencies: map[string]cgjson.EdgeInfo{}}
+			}
+		}
+		report.Leaves[source].Dependencies[target] = cgjson.EdgeInfo{IsCyclic: true, Weight: weight, Type: "usage"}
+	}
+	return report
+}
+
+func TestSuggestCutsTheFeedbackEdgesOfLayered(t *testing.T) {
+	// given
+	report := cgjsontest.Layered(t)
+
+	// when
+	plan := Suggest(report, 100)
+
+	// then
+	expected := Plan{
+		Components: []Component{{Leaves: []string{"app.adapter.Db", "app.domain.Model", "app.domain.Repository"}, Edges: 5, Weight: 8, Cycles: 3, Complete: true, CutWeight: 3}},
+		Cuts: []Cut{
+			{Rank: 1, Source: "app.domain.Repository", Target: "app.domain.Model", Weight: 1, EdgeType: cgjson.FeedbackLeafLevel, Cycles: 2, Component: 1},
+			{Rank: 2, Source: "app.domain.Model", Target: "app.adapter.Db", Weight: 2, EdgeType: cgjson.FeedbackLeafLevel, Cycles: 2, Component: 1},
+		},
+	}
+	if !reflect.DeepEqual(plan, expected) {
+		t.Errorf("got %+v", plan)
+	}
+}
+
+func TestSuggestPrefersLightDependencies(t *testing.T) {
+	// given
+	report := cyclic("a", "b", 5, "b", "c", 4, "c", "a", 1)
+
+	// when
+	plan := Suggest(report, 100)
+
+	// then
+	if len(plan.Cuts) != 1 || plan.Cuts[0].Source != "c" || plan.Cuts[0].Target != "a" || plan.Components[0].CutWeight != 1 {
+		t.Errorf("got %+v", plan)
+	}
+}
+
+func TestSuggestCutsSharedDependencyOfSeveralCycles(t *testing.T) {
+	// given
+	report := cyclic(
+		"hub", "core", 1,
+		"core", "a", 2, "a", "hub", 2,
+		"core", "b", 2, "b", "hub", 2,
+		"core", "c", 2, "c", "hub", 2,
+	)
+
+	// when
+	plan := Suggest(report, 100)
+
+	// then
+	expected := []Cut{{Rank: 1, Source: "hub", Target: "core", Weight: 1, EdgeType: cgjson.Cyclic, Cycles: 3, Component: 1}}
+	if !reflect.DeepEqual(plan.Cuts, expected) || plan.Components[0].Cycles != 3 {
+		t.Errorf("got %+v", plan)
+	}
+}
+
+func TestSuggestRanksCutsByCyclesPerWeight(t *testing.T) {
+	// given
+	report := cyclic(
+		"a", "b", 1, "b", "a", 3,
+		"x", "y", 4, "y", "z", 4, "z", "x", 2, "y", "x", 4, "z", "y", 4,
+	)
+
+	// when
+	plan := Suggest(report, 100)
+
+	// then
+	var ranked [][2]string
+	for _, cut := range plan.Cuts {
+		ranked = append(ranked, [2]string{cut.Source, cut.Target})
+	}
+	if len(plan.Components) != 2 || len(ranked) == 0 || ranked[0] != [2]string{"a", "b"} {
+		t.Errorf("got %+v", plan)
+	}
+	for i, cut := range plan.Cuts {
+		if cut.Rank != i+1 {
+			t.Errorf("cut %d has rank %d", i, cut.Rank)
+		}
+	}
+}
+
+func TestSuggestBreaksAllCycles(t *testing.T) {
+	// given
+	report := cyclic(
+		"a", "b", 1, "b", "c", 2, "c", "a", 3, "c", "d", 1,
+		"d", "b", 2, "d", "a", 1, "a", "d", 4, "b", "a", 1,
+	)
+
+	// when
+	plan := Suggest(report, 100)
+
+	// then
+	for _, cut := range plan.Cuts {
+		delete(report.Leaves[cut.Source].Dependencies, cut.Target)
+	}
+	successors := map[string][]string{}
+	for edge := range report.Edges() {
+		successors[edge.Source] = append(successors[edge.Source], edge.Target)
+	}
+	if components := cgjson.StronglyConnectedComponents(successors); len(components) > 0 {
+		t.Errorf("cycles left in %v after cutting %+v", components, plan.Cuts)
+	}
+}
+
+func TestSuggestStopsCountingCyclesAtLimit(t *testing.T) {
+	// given
+	report := cyclic("a", "b", 1, "b", "a", 1, "b", "c", 1, "c", "a", 1)
+
+	// when
+	plan := Suggest(report, 1)
+
+	// then
+	if plan.Components[0].Cycles != 1 || plan.Components[0].Complete {
+		t.Errorf("got %+v", plan.Components)
+	}
+}
+
+func TestSuggestStopsSearchingCyclesAfterLimitStepsPerLeaf(t *testing.T) {
+	// given
+	report := cyclic("a", "b", 1, "b", "c", 1, "c", "d", 1, "d", "a", 1)
+
+	// when
+	plan := Suggest(report, 2)
+
+	// then
+	if plan.Components[0].Cycles != 0 || plan.Components[0].Complete {
+		t.Errorf("got %+v", plan.Components)
+	}
+}
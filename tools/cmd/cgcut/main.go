@@ -0,0 +1,87 @@
+// Command cgcut suggests which dependencies of a .cg.json file to cut to break
+// its cycles, ranked by how many cycles each cut breaks per unit of weight.
+//
+// Usage:
+//
+//	cgcut [-json] [-top n] [-max-cycles n] analysis.cg.json
+//
+// Every strongly connected component of cyclic dependencies gets a small set
+// of dependencies whose removal makes it acyclic, computed with a weighted
+// feedback arc set heuristic that prefers dependencies with a low weight.
+// Simple cycles are counted up to -max-cycles per component to rank the cuts.
+// The exit code is 0 if there are no cycles and 1 if cuts are suggested.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+)
+
+func main() {
+	asJSON := flag.Bool("json", false, "print the plan as JSON")
+	top := flag.Int("top", 0, "print only the n best cuts, 0 for all")
+	maxCycles := flag.Int("max-cycles", 10000, "stop counting the cycles of a component after this many")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: cgcut [-json] [-top n] [-max-cycles n] analysis.cg.json\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 || *top < 0 || *maxCycles < 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	report, err := cgjson.ReadFile(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	plan := Suggest(report, *maxCycles)
+	if *top > 0 && len(plan.Cuts) > *top {
+		plan.Cuts = plan.Cuts[:*top]
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		err = encoder.Encode(plan)
+	} else {
+		err = writeText(os.Stdout, plan)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if len(plan.Components) > 0 {
+		os.Exit(1)
+	}
+}
+
+func writeText(w io.Writer, plan Plan) error {
+	if len(plan.Components) == 0 {
+		_, err := fmt.Fprintln(w, "no cycles")
+		return err
+	}
+	out := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(out, "COMPONENT\tLEAVES\tEDGES\tWEIGHT\tCYCLES\tCUT WEIGHT")
+	for i, component := range plan.Components {
+		cycles := fmt.Sprint(component.Cycles)
+		if !component.Complete {
+			cycles = "≥" + cycles
+		}
+		fmt.Fprintf(out, "%d\t%d\t%d\t%d\t%s\t%d\n", i+1, len(component.Leaves), component.Edges, component.Weight, cycles, component.CutWeight)
+	}
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "RANK\tCUT\tTYPE\tWEIGHT\tCYCLES\tCOMPONENT")
+	for _, cut := range plan.Cuts {
+		fmt.Fprintf(out, "%d\t%s -> %s\t%s\t%d\t%d\t%d\n", cut.Rank, cut.Source, cut.Target, cut.EdgeType, cut.Weight, cut.Cycles, cut.Component)
+	}
+	return out.Flush()
+}
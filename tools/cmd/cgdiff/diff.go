@@ -0,0 +1,185 @@
+package main
+
+import (
+	"sort"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+)
+
+// Delta is the architectural difference between a base and a head analysis.
+type Delta struct {
+	AddedLeaves           []string      `json:"addedLeaves"`
+	RemovedLeaves         []string      `json:"removedLeaves"`
+	NewCyclicEdges        []EdgeChange  `json:"newCyclicEdges"`
+	ResolvedCyclicEdges   []EdgeChange  `json:"resolvedCyclicEdges"`
+	NewFeedbackEdges      []EdgeChange  `json:"newFeedbackEdges"`
+	ResolvedFeedbackEdges []EdgeChange  `json:"resolvedFeedbackEdges"`
+	ChangedFeedbackEdges  []TypeChange  `json:"changedFeedbackEdges"`
+	LevelChanges          []LevelChange `json:"levelChanges"`
+}
+
+// EdgeChange is an edge that became or stopped being cyclic or a feedback
+// edge. EdgeType is the type in the analysis the edge has this property in.
+type EdgeChange struct {
+	Source   string          `json:"source"`
+	Target   string          `json:"target"`
+	EdgeType cgjson.EdgeType `json:"edgeType"`
+	Weight   int             `json:"weight"`
+}
+
+// TypeChange is a feedback edge in both analyses whose edge type changed
+// between FEEDBACK_CONTAINER_LEVEL and FEEDBACK_LEAF_LEVEL. Weight is the
+// weight in head.
+type TypeChange struct {
+	Source      string          `json:"source"`
+	Target      string          `json:"target"`
+	OldEdgeType cgjson.EdgeType `json:"oldEdgeType"`
+	NewEdgeType cgjson.EdgeType `json:"newEdgeType"`
+	Weight      int             `json:"weight"`
+}
+
+// LevelChange is a namespace whose level differs between the analyses.
+type LevelChange struct {
+	Namespace string `json:"namespace"`
+	OldLevel  int    `json:"oldLevel"`
+	NewLevel  int    `json:"newLevel"`
+}
+
+// HasRegressions reports whether head introduced cyclic or feedback edges.
+func (d Delta) HasRegressions() bool {
+	return len(d.NewCyclicEdges) > 0 || len(d.NewFeedbackEdges) > 0
+}
+
+// CountFeedbackEdges returns how many of the new feedback edges are on leaf
+// and on container level.
+func CountFeedbackEdges(edges []EdgeChange) (leafLevel, containerLevel int) {
+	for _, edge := range edges {
+		if edge.EdgeType == cgjson.FeedbackLeafLevel {
+			leafLevel++
+		} else {
+			containerLevel++
+		}
+	}
+	return leafLevel, containerLevel
+}
+
+// Compare computes the delta from base to head.
+func Compare(base, head *cgjson.ProjectReport) Delta {
+	delta := Delta{
+		AddedLeaves:           missingIn(head.LeafIDs(), base.Leaves),
+		RemovedLeaves:         missingIn(base.LeafIDs(), head.Leaves),
+		NewCyclicEdges:        []EdgeChange{},
+		ResolvedCyclicEdges:   []EdgeChange{},
+		NewFeedbackEdges:      []EdgeChange{},
+		ResolvedFeedbackEdges: []EdgeChange{},
+		ChangedFeedbackEdges:  []TypeChange{},
+		LevelChanges:          []LevelChange{},
+	}
+
+	baseEdges := edgesByKey(base)
+	headEdges := edgesByKey(head)
+
+	for _, key := range sortedEdgeKeys(headEdges) {
+		edge := headEdges[key]
+		old, existed := baseEdges[key]
+		if edge.IsCyclic && !(existed && old.IsCyclic) {
+			delta.NewCyclicEdges = append(delta.NewCyclicEdges, change(edge))
+		}
+		switch {
+		case !isFeedback(edge):
+		case !existed || !isFeedback(old):
+			delta.NewFeedbackEdges = append(delta.NewFeedbackEdges, change(edge))
+		case old.EdgeType() != edge.EdgeType():
+			delta.ChangedFeedbackEdges = append(delta.ChangedFeedbackEdges, TypeChange{
+				Source:      edge.Source,
+				Target:      edge.Target,
+				OldEdgeType: old.EdgeType(),
+				NewEdgeType: edge.EdgeType(),
+				Weight:      edge.Weight,
+			})
+		}
+	}
+	for _, key := range sortedEdgeKeys(baseEdges) {
+		edge := baseEdges[key]
+		current, exists := headEdges[key]
+		if edge.IsCyclic && !(exists && current.IsCyclic) {
+			delta.ResolvedCyclicEdges = append(delta.ResolvedCyclicEdges, change(edge))
+		}
+		if isFeedback(edge) && !(exists && isFeedback(current)) {
+			delta.ResolvedFeedbackEdges = append(delta.ResolvedFeedbackEdges, change(edge))
+		}
+	}
+
+	baseLevels := namespaceLevels(base)
+	headLevels := namespaceLevels(head)
+	namespaces := make([]string, 0, len(headLevels))
+	for namespace := range headLevels {
+		namespaces = append(namespaces, namespace)
+	}
+	sort.Strings(namespaces)
+	for _, namespace := range namespaces {
+		if oldLevel, existed := baseLevels[namespace]; existed && oldLevel != headLevels[namespace] {
+			delta.LevelChanges = append(delta.LevelChanges, LevelChange{Namespace: namespace, OldLevel: oldLevel, NewLevel: headLevels[namespace]})
+		}
+	}
+	return delta
+}
+
+type edgeKey struct {
+	source string
+	target string
+}
+
+func edgesByKey(report *cgjson.ProjectReport) map[edgeKey]cgjson.Edge {
+	edges := make(map[edgeKey]cgjson.Edge)
+	for edge := range report.Edges() {
+		edges[edgeKey{edge.Source, edge.Target}] = edge
+	}
+	return edges
+}
+
+func sortedEdgeKeys(edges map[edgeKey]cgjson.Edge) []edgeKey {
+	keys := make([]edgeKey, 0, len(edges))
+	for key := range edges {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].source != keys[j].source {
+			return keys[i].source < keys[j].source
+		}
+		return keys[i].target < keys[j].target
+	})
+	return keys
+}
+
+func isFeedback(edge cgjson.Edge) bool {
+	edgeType := edge.EdgeType()
+	return edgeType == cgjson.FeedbackLeafLevel || edgeType == cgjson.FeedbackContainerLevel
+}
+
+func change(edge cgjson.Edge) EdgeChange {
+	return EdgeChange{Source: edge.Source, Target: edge.Target, EdgeType: edge.EdgeType(), Weight: edge.Weight}
+}
+
+// namespaceLevels returns the level of every namespace node by its path.
+func namespaceLevels(report *cgjson.ProjectReport) map[string]int {
+	index := cgjson.NewIndex(report)
+	levels := make(map[string]int)
+	for node := range report.Nodes() {
+		if !node.IsLeaf() {
+			levels[index.Path(node)] = node.Level
+		}
+	}
+	return levels
+}
+
+// missingIn returns the ids that are not keys of leaves, keeping their order.
+func missingIn(ids []string, leaves map[string]*cgjson.LeafInformation) []string {
+	missing := []string{}
+	for _, id := range ids {
+		if _, exists := leaves[id]; !exists {
+			missing = append(missing, id)
+		}
+	}
+	return missing
+}
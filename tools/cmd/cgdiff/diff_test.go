@@ -0,0 +1,121 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson/cgjsontest"
+)
+
+func TestCompareIdenticalReportsHasNoDelta(t *testing.T) {
+	// given
+	base, head := cgjsontest.Layered(t), cgjsontest.Layered(t)
+
+	// when
+	delta := Compare(base, head)
+
+	// then
+	if delta.HasRegressions() || len(delta.AddedLeaves)+len(delta.RemovedLeaves)+len(delta.LevelChanges) > 0 {
+		t.Errorf("unexpected delta %+v", delta)
+	}
+}
+
+func TestCompareReportsNewFeedbackAndCyclicEdges(t *testing.T) {
+	// given
+	base, head := cgjsontest.Layered(t), cgjsontest.Layered(t)
+	head.Leaves["app.adapter.Db"].Dependencies["app.adapter.Http"] = cgjson.EdgeInfo{Weight: 1, Type: "usage"}
+	head.Recompute()
+
+	// when
+	delta := Compare(base, head)
+
+	// then
+	feedback := EdgeChange{Source: "app.adapter.Http", Target: "app.adapter.Db", EdgeType: cgjson.FeedbackLeafLevel, Weight: 1}
+	cyclic := []EdgeChange{{Source: "app.adapter.Db", Target: "app.adapter.Http", EdgeType: cgjson.Cyclic, Weight: 1}, feedback}
+	if !reflect.DeepEqual(delta.NewFeedbackEdges, []EdgeChange{feedback}) || !reflect.DeepEqual(delta.NewCyclicEdges, cyclic) {
+		t.Errorf("unexpected delta %+v", delta)
+	}
+	if !delta.HasRegressions() {
+		t.Error("expected regressions")
+	}
+}
+
+func TestCompareReportsResolvedEdgesAndLeafChanges(t *testing.T) {
+	// given
+	base, head := cgjsontest.Layered(t), cgjsontest.Layered(t)
+	delete(head.Leaves, "app.domain.Repository")
+	delete(head.Leaves["app.domain.Model"].Dependencies, "app.domain.Repository")
+	delete(head.Leaves["app.adapter.Db"].Dependencies, "app.domain.Repository")
+	head.Leaves["app.adapter.Cache"] = &cgjson.LeafInformation{ID: "app.adapter.Cache"}
+
+	// when
+	delta := Compare(base, head)
+
+	// then
+	if !reflect.DeepEqual(delta.AddedLeaves, []string{"app.adapter.Cache"}) || !reflect.DeepEqual(delta.RemovedLeaves, []string{"app.domain.Repository"}) {
+		t.Errorf("unexpected leaf changes %+v", delta)
+	}
+	expectedCyclic := []EdgeChange{
+		{Source: "app.adapter.Db", Target: "app.domain.Repository", EdgeType: cgjson.Cyclic, Weight: 1},
+		{Source: "app.domain.Model", Target: "app.domain.Repository", EdgeType: cgjson.Cyclic, Weight: 1},
+		{Source: "app.domain.Repository", Target: "app.domain.Model", EdgeType: cgjson.FeedbackLeafLevel, Weight: 1},
+	}
+	if !reflect.DeepEqual(delta.ResolvedCyclicEdges, expectedCyclic) {
+		t.Errorf("got resolved cyclic edges %+v", delta.ResolvedCyclicEdges)
+	}
+	expectedFeedback := []EdgeChange{{Source: "app.domain.Repository", Target: "app.domain.Model", EdgeType: cgjson.FeedbackLeafLevel, Weight: 1}}
+	if !reflect.DeepEqual(delta.ResolvedFeedbackEdges, expectedFeedback) {
+		t.Errorf("got resolved feedback edges %+v", delta.ResolvedFeedbackEdges)
+	}
+}
+
+func TestCompareIgnoresSelfDependencies(t *testing.T) {
+	// given
+	base, head := cgjsontest.Layered(t), cgjsontest.Layered(t)
+	head.Leaves["app.adapter.Http"].Dependencies["app.adapter.Http"] = cgjson.EdgeInfo{Weight: 1, Type: "usage"}
+	httpNode, _ := cgjson.NewIndex(head).LeafNode("app.adapter.Http")
+	httpNode.ContainedInternalDependencies["app.adapter.Http"] = cgjson.EdgeInfo{Weight: 1, Type: "usage", IsPointingUpwards: true}
+
+	// when
+	delta := Compare(base, head)
+
+	// then
+	if delta.HasRegressions() || len(delta.NewFeedbackEdges) > 0 {
+		t.Errorf("unexpected delta %+v", delta)
+	}
+}
+
+func TestCompareReportsFeedbackTypeChangesSeparately(t *testing.T) {
+	// given
+	base, head := cgjsontest.Decoupled(t), cgjsontest.Decoupled(t)
+	head.Leaves["app.domain.Repository"].Dependencies["app.adapter.Http"] = cgjson.EdgeInfo{Weight: 1, Type: "usage"}
+	head.Recompute()
+
+	// when
+	delta := Compare(base, head)
+
+	// then
+	expected := []TypeChange{{Source: "app.adapter.Http", Target: "app.domain.Repository", OldEdgeType: cgjson.FeedbackContainerLevel, NewEdgeType: cgjson.FeedbackLeafLevel, Weight: 1}}
+	if !reflect.DeepEqual(delta.ChangedFeedbackEdges, expected) {
+		t.Errorf("got changed feedback edges %+v", delta.ChangedFeedbackEdges)
+	}
+	if len(delta.NewFeedbackEdges)+len(delta.ResolvedFeedbackEdges) > 0 {
+		t.Errorf("type change counted as new or resolved %+v", delta)
+	}
+}
+
+func TestCompareReportsNamespaceLevelChanges(t *testing.T) {
+	// given
+	base, head := cgjsontest.Layered(t), cgjsontest.Layered(t)
+	head.ProjectTreeRoots[0].Children[1].Level = 2
+
+	// when
+	delta := Compare(base, head)
+
+	// then
+	expected := []LevelChange{{Namespace: "app.adapter", OldLevel: 1, NewLevel: 2}}
+	if !reflect.DeepEqual(delta.LevelChanges, expected) {
+		t.Errorf("got %+v", delta.LevelChanges)
+	}
+}
@@ -0,0 +1,125 @@
+// Command cgdiff reports the architectural delta between two .cg.json files,
+// e.g. the analyses of the main branch and of a feature branch.
+//
+// Usage:
+//
+//	cgdiff [-json] [-fail] base.cg.json head.cg.json
+//
+// It lists added and removed leaves, new and resolved cyclic edges, new and
+// resolved feedback edges, feedback edges whose type changed and level changes
+// per namespace. With -fail the exit code is 1 if head introduced cyclic or
+// feedback edges.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+)
+
+func main() {
+	asJSON := flag.Bool("json", false, "print the delta as JSON")
+	fail := flag.Bool("fail", false, "exit with 1 if new cyclic or feedback edges were introduced")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: cgdiff [-json] [-fail] base.cg.json head.cg.json\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	base, err := cgjson.ReadFile(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	head, err := cgjson.ReadFile(flag.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	delta := Compare(base, head)
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(delta); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	} else {
+		writeText(os.Stdout, delta)
+	}
+
+	if *fail && delta.HasRegressions() {
+		os.Exit(1)
+	}
+}
+
+func writeText(w io.Writer, delta Delta) {
+	leafLevel, containerLevel := CountFeedbackEdges(delta.NewFeedbackEdges)
+	fmt.Fprintf(w, "This change introduced %s (%d leaf level, %d container level) and %s.\n",
+		upwardDependencies(len(delta.NewFeedbackEdges)), leafLevel, containerLevel, cyclicEdges(len(delta.NewCyclicEdges)))
+	fmt.Fprintf(w, "It resolved %s and %s.\n",
+		upwardDependencies(len(delta.ResolvedFeedbackEdges)), cyclicEdges(len(delta.ResolvedCyclicEdges)))
+
+	writeList(w, "Added leaves", delta.AddedLeaves)
+	writeList(w, "Removed leaves", delta.RemovedLeaves)
+	writeEdges(w, "New upward dependencies", delta.NewFeedbackEdges)
+	writeEdges(w, "Resolved upward dependencies", delta.ResolvedFeedbackEdges)
+	if len(delta.ChangedFeedbackEdges) > 0 {
+		fmt.Fprintf(w, "\nChanged upward dependencies (%d):\n", len(delta.ChangedFeedbackEdges))
+		for _, change := range delta.ChangedFeedbackEdges {
+			fmt.Fprintf(w, "  %s -> %s [%s -> %s, weight %d]\n", change.Source, change.Target, change.OldEdgeType, change.NewEdgeType, change.Weight)
+		}
+	}
+	writeEdges(w, "New cyclic edges", delta.NewCyclicEdges)
+	writeEdges(w, "Resolved cyclic edges", delta.ResolvedCyclicEdges)
+
+	if len(delta.LevelChanges) > 0 {
+		fmt.Fprintf(w, "\nLevel changes (%d):\n", len(delta.LevelChanges))
+		for _, change := range delta.LevelChanges {
+			fmt.Fprintf(w, "  %s: %d -> %d\n", change.Namespace, change.OldLevel, change.NewLevel)
+		}
+	}
+}
+
+func upwardDependencies(count int) string {
+	if count == 1 {
+		return "1 upward dependency"
+	}
+	return fmt.Sprintf("%d upward dependencies", count)
+}
+
+func cyclicEdges(count int) string {
+	if count == 1 {
+		return "1 cyclic edge"
+	}
+	return fmt.Sprintf("%d cyclic edges", count)
+}
+
+func writeList(w io.Writer, title string, entries []string) {
+	if len(entries) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%s (%d):\n", title, len(entries))
+	for _, entry := range entries {
+		fmt.Fprintf(w, "  %s\n", entry)
+	}
+}
+
+func writeEdges(w io.Writer, title string, edges []EdgeChange) {
+	if len(edges) == 0 {
+		return
+	}
+	fmt.Fprintf(w, "\n%s (%d):\n", title, len(edges))
+	for _, edge := range edges {
+		fmt.Fprintf(w, "  %s -> %s [%s, weight %d]\n", edge.Source, edge.Target, edge.EdgeType, edge.Weight)
+	}
+}
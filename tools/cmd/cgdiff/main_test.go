@@ -0,0 +1,26 @@
+package main
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+)
+
+func TestWriteTextPluralisesTheSummary(t *testing.T) {
+	// given
+	feedback := EdgeChange{Source: "app.adapter.Http", Target: "app.adapter.Db", EdgeType: cgjson.FeedbackLeafLevel, Weight: 1}
+	cyclic := EdgeChange{Source: "app.adapter.Db", Target: "app.adapter.Http", EdgeType: cgjson.Cyclic, Weight: 1}
+	delta := Delta{NewFeedbackEdges: []EdgeChange{feedback}, NewCyclicEdges: []EdgeChange{cyclic}, ResolvedCyclicEdges: []EdgeChange{cyclic, feedback}}
+
+	// when
+	var output strings.Builder
+	writeText(&output, delta)
+
+	// then
+	expected := "This change introduced 1 upward dependency (1 leaf level, 0 container level) and 1 cyclic edge.\n" +
+		"It resolved 0 upward dependencies and 2 cyclic edges.\n"
+	if !strings.HasPrefix(output.String(), expected) {
+		t.Errorf("got %q", output.String())
+	}
+}
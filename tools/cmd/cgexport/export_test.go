@@ -0,0 +1,121 @@
+package main
+
+import (
+	"encoding/xml"
+	"io"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson/cgjsontest"
+)
+
+func build(t *testing.T, depth int) *Graph {
+	t.Helper()
+	return Build(cgjsontest.Layered(t), depth)
+}
+
+func render(t *testing.T, format string, graph *Graph) string {
+	t.Helper()
+	var out strings.Builder
+	if err := writers[format](&out, graph); err != nil {
+		t.Fatal(err)
+	}
+	return out.String()
+}
+
+func TestBuildCollapsesNamespacesAtDepth(t *testing.T) {
+	// when
+	graph := build(t, 2)
+
+	// then
+	expected := []*Node{{ID: "app", Label: "app", Children: []*Node{
+		{ID: "app.domain", Label: "domain"},
+		{ID: "app.adapter", Label: "adapter"},
+	}}}
+	if !reflect.DeepEqual(graph.Roots, expected) {
+		t.Errorf("got %+v", graph.Roots)
+	}
+	if len(graph.Edges) != 2 || graph.Edges[0].Weight != 4 || !graph.Aggregated {
+		t.Errorf("got %+v", graph.Edges)
+	}
+}
+
+func TestDOTStylesEdgesByType(t *testing.T) {
+	// when
+	dot := render(t, "dot", build(t, 0)) + render(t, "dot", Build(cgjsontest.Decoupled(t), 0))
+
+	// then
+	for _, line := range []string{
+		`subgraph "cluster_app.domain" {`,
+		`"app.domain.Model" [label="Model"];`,
+		`"app.adapter.Http" -> "app.adapter.Db" [color="#808080"];`,
+		`"app.domain.Model" -> "app.domain.Repository" [color="#0000FF"];`,
+		`"app.adapter.Http" -> "app.domain.Repository" [color="#FF0000", style=dotted];`,
+		`"app.domain.Repository" -> "app.domain.Model" [color="#FF0000"];`,
+	} {
+		if !strings.Contains(dot, line) {
+			t.Errorf("missing %s in\n%s", line, dot)
+		}
+	}
+}
+
+func TestMermaidLabelsAggregatedWeights(t *testing.T) {
+	// when
+	mermaid := render(t, "mermaid", build(t, 2))
+
+	// then
+	expected := `flowchart LR
+  subgraph n1["app"]
+    n2[["domain"]]
+    n3[["adapter"]]
+  end
+  n3 -->|4| n2
+  n2 -->|2| n3
+  linkStyle 0 stroke:#0000FF
+  linkStyle 1 stroke:#FF0000
+`
+	if mermaid != expected {
+		t.Errorf("got\n%s\nwant\n%s", mermaid, expected)
+	}
+}
+
+func TestPlantUMLNestsPackages(t *testing.T) {
+	// when
+	plantUML := render(t, "plantuml", Build(cgjsontest.Decoupled(t), 0))
+
+	// then
+	for _, line := range []string{"@startuml\n", "\n  package \"domain\" as n2 {\n", "\n    rectangle \"Model\" as n3\n", "\nn7 -[#FF0000,dotted]-> n4\n", "@enduml\n"} {
+		if !strings.Contains(plantUML, line) {
+			t.Errorf("missing %q in\n%s", line, plantUML)
+		}
+	}
+}
+
+func TestGraphMLIsWellFormed(t *testing.T) {
+	// given
+	graph := build(t, 0)
+	graph.Roots[0].Children[0].Children[0].Label = "Model<T & U>"
+
+	// when
+	graphML := render(t, "graphml", graph)
+
+	// then
+	decoder := xml.NewDecoder(strings.NewReader(graphML))
+	edges := 0
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("%v in\n%s", err, graphML)
+		}
+		if element, ok := token.(xml.StartElement); ok && element.Name.Local == "edge" {
+			edges++
+		}
+	}
+	if edges != 6 {
+		t.Errorf("got %d edges", edges)
+	}
+}
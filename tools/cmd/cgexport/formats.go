@@ -0,0 +1,202 @@
+package main
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// writers maps the -format values to the functions rendering a graph.
+var writers = map[string]func(io.Writer, *Graph) error{
+	"dot":      writeDOT,
+	"graphml":  writeGraphML,
+	"mermaid":  writeMermaid,
+	"plantuml": writePlantUML,
+}
+
+func indent(depth int) string {
+	return strings.Repeat("  ", depth+1)
+}
+
+// writeDOT renders the graph for Graphviz. Clusters become cluster subgraphs
+// and collapsed namespaces folder-shaped nodes.
+func writeDOT(w io.Writer, graph *Graph) error {
+	var out strings.Builder
+	out.WriteString("digraph dependencies {\n  compound=true;\n  node [shape=box];\n")
+	walk(graph.Roots, func(node *Node, depth int) {
+		switch {
+		case node.IsCluster():
+			fmt.Fprintf(&out, "%ssubgraph %s {\n%s  label=%s;\n", indent(depth), dotQuote("cluster_"+node.ID), indent(depth), dotQuote(node.Label))
+		case node.Leaf:
+			fmt.Fprintf(&out, "%s%s [label=%s];\n", indent(depth), dotQuote(node.ID), dotQuote(node.Label))
+		default:
+			fmt.Fprintf(&out, "%s%s [label=%s, shape=folder];\n", indent(depth), dotQuote(node.ID), dotQuote(node.Label))
+		}
+	}, func(node *Node, depth int) {
+		fmt.Fprintf(&out, "%s}\n", indent(depth))
+	})
+	for _, edge := range graph.Edges {
+		style := edgeStyle(edge.EdgeType())
+		attributes := []string{"color=" + dotQuote(style.Color)}
+		if style.Dotted {
+			attributes = append(attributes, "style=dotted")
+		}
+		if graph.Aggregated {
+			attributes = append(attributes, "label="+dotQuote(strconv.Itoa(edge.Weight)))
+		}
+		fmt.Fprintf(&out, "  %s -> %s [%s];\n", dotQuote(edge.Source), dotQuote(edge.Target), strings.Join(attributes, ", "))
+	}
+	out.WriteString("}\n")
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+func dotQuote(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(s) + `"`
+}
+
+// writeMermaid renders a Mermaid flowchart. Mermaid ids must be plain words,
+// so nodes are numbered and the edges are styled by their index.
+func writeMermaid(w io.Writer, graph *Graph) error {
+	ids := numberNodes(graph)
+	var out strings.Builder
+	out.WriteString("flowchart LR\n")
+	walk(graph.Roots, func(node *Node, depth int) {
+		label := mermaidQuote(node.Label)
+		switch {
+		case node.IsCluster():
+			fmt.Fprintf(&out, "%ssubgraph %s[%s]\n", indent(depth), ids[node.ID], label)
+		case node.Leaf:
+			fmt.Fprintf(&out, "%s%s[%s]\n", indent(depth), ids[node.ID], label)
+		default:
+			fmt.Fprintf(&out, "%s%s[[%s]]\n", indent(depth), ids[node.ID], label)
+		}
+	}, func(node *Node, depth int) {
+		fmt.Fprintf(&out, "%send\n", indent(depth))
+	})
+	var linkStyles strings.Builder
+	for i, edge := range graph.Edges {
+		style := edgeStyle(edge.EdgeType())
+		arrow := "-->"
+		if style.Dotted {
+			arrow = "-.->"
+		}
+		if graph.Aggregated {
+			arrow += "|" + strconv.Itoa(edge.Weight) + "|"
+		}
+		fmt.Fprintf(&out, "  %s %s %s\n", ids[edge.Source], arrow, ids[edge.Target])
+		fmt.Fprintf(&linkStyles, "  linkStyle %d stroke:%s\n", i, style.Color)
+	}
+	out.WriteString(linkStyles.String())
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+func mermaidQuote(s string) string {
+	return `"` + strings.NewReplacer(`"`, "#quot;", "<", "#lt;", ">", "#gt;").Replace(s) + `"`
+}
+
+// writePlantUML renders a PlantUML diagram with packages for the clusters,
+// folders for collapsed namespaces and rectangles for the leaves.
+func writePlantUML(w io.Writer, graph *Graph) error {
+	ids := numberNodes(graph)
+	var out strings.Builder
+	out.WriteString("@startuml\n")
+	walk(graph.Roots, func(node *Node, depth int) {
+		label := `"` + strings.ReplaceAll(node.Label, `"`, "'") + `"`
+		switch {
+		case node.IsCluster():
+			fmt.Fprintf(&out, "%spackage %s as %s {\n", indent(depth-1), label, ids[node.ID])
+		case node.Leaf:
+			fmt.Fprintf(&out, "%srectangle %s as %s\n", indent(depth-1), label, ids[node.ID])
+		default:
+			fmt.Fprintf(&out, "%sfolder %s as %s\n", indent(depth-1), label, ids[node.ID])
+		}
+	}, func(node *Node, depth int) {
+		fmt.Fprintf(&out, "%s}\n", indent(depth-1))
+	})
+	for _, edge := range graph.Edges {
+		style := edgeStyle(edge.EdgeType())
+		attributes := style.Color
+		if style.Dotted {
+			attributes += ",dotted"
+		}
+		fmt.Fprintf(&out, "%s -[%s]-> %s", ids[edge.Source], attributes, ids[edge.Target])
+		if graph.Aggregated {
+			fmt.Fprintf(&out, " : %d", edge.Weight)
+		}
+		out.WriteString("\n")
+	}
+	out.WriteString("@enduml\n")
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+// numberNodes assigns the ids n1, n2, ... to the nodes in pre-order.
+func numberNodes(graph *Graph) map[string]string {
+	ids := make(map[string]string)
+	walk(graph.Roots, func(node *Node, _ int) {
+		ids[node.ID] = "n" + strconv.Itoa(len(ids)+1)
+	}, func(*Node, int) {})
+	return ids
+}
+
+// writeGraphML renders GraphML with nested graphs for the clusters. Edges
+// carry their weight, edge type, flags and the colour and line style the
+// visualization uses.
+func writeGraphML(w io.Writer, graph *Graph) error {
+	var out strings.Builder
+	out.WriteString(xml.Header)
+	out.WriteString(`<graphml xmlns="http://graphml.graphdrawing.org/xmlns">` + "\n")
+	for _, key := range []struct{ id, domain, kind string }{
+		{"label", "node", "string"},
+		{"kind", "node", "string"},
+		{"weight", "edge", "int"},
+		{"edgeType", "edge", "string"},
+		{"isCyclic", "edge", "boolean"},
+		{"isPointingUpwards", "edge", "boolean"},
+		{"color", "edge", "string"},
+		{"lineStyle", "edge", "string"},
+	} {
+		fmt.Fprintf(&out, `  <key id="%s" for="%s" attr.name="%s" attr.type="%s"/>`+"\n", key.id, key.domain, key.id, key.kind)
+	}
+	out.WriteString(`  <graph id="dependencies" edgedefault="directed">` + "\n")
+	walk(graph.Roots, func(node *Node, depth int) {
+		prefix := strings.Repeat("  ", 2*depth+2)
+		kind := "namespace"
+		if node.Leaf {
+			kind = "leaf"
+		}
+		fmt.Fprintf(&out, `%s<node id="%s"><data key="label">%s</data><data key="kind">%s</data>`, prefix, xmlEscape(node.ID), xmlEscape(node.Label), kind)
+		if node.IsCluster() {
+			fmt.Fprintf(&out, "\n%s  <graph id=\"%s:\" edgedefault=\"directed\">\n", prefix, xmlEscape(node.ID))
+		} else {
+			out.WriteString("</node>\n")
+		}
+	}, func(node *Node, depth int) {
+		prefix := strings.Repeat("  ", 2*depth+2)
+		fmt.Fprintf(&out, "%s  </graph>\n%s</node>\n", prefix, prefix)
+	})
+	for _, edge := range graph.Edges {
+		style := edgeStyle(edge.EdgeType())
+		lineStyle := "solid"
+		if style.Dotted {
+			lineStyle = "dotted"
+		}
+		fmt.Fprintf(&out, `    <edge source="%s" target="%s">`, xmlEscape(edge.Source), xmlEscape(edge.Target))
+		fmt.Fprintf(&out, `<data key="weight">%d</data><data key="edgeType">%s</data><data key="isCyclic">%t</data><data key="isPointingUpwards">%t</data>`,
+			edge.Weight, edge.EdgeType(), edge.IsCyclic, edge.IsPointingUpwards)
+		fmt.Fprintf(&out, `<data key="color">%s</data><data key="lineStyle">%s</data></edge>`+"\n", style.Color, lineStyle)
+	}
+	out.WriteString("  </graph>\n</graphml>\n")
+	_, err := io.WriteString(w, out.String())
+	return err
+}
+
+func xmlEscape(s string) string {
+	var escaped strings.Builder
+	xml.EscapeText(&escaped, []byte(s))
+	return escaped.String()
+}
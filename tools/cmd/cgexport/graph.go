@@ -0,0 +1,98 @@
+package main
+
+import (
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+)
+
+// Graph is the project tree cut at the collapse depth together with the
+// edges between its visible nodes.
+type Graph struct {
+	Roots []*Node
+	Edges []cgjson.Edge
+	// Aggregated is set if namespaces were collapsed, so that edge weights
+	// are sums worth showing.
+	Aggregated bool
+}
+
+// Node is a visible node: a leaf, a namespace drawn as a cluster around its
+// children, or a collapsed namespace drawn as a single node.
+type Node struct {
+	ID       string
+	Label    string
+	Leaf     bool
+	Children []*Node
+}
+
+// IsCluster reports whether the node is drawn as a cluster around its
+// children.
+func (n *Node) IsCluster() bool {
+	return len(n.Children) > 0
+}
+
+// Build converts report into a graph. With depth > 0, namespaces at that depth
+// are collapsed, counting the roots as depth 1, and the edges between their
+// leaves are aggregated with cgjson.Aggregate. Dependencies of a node on
+// itself are left out.
+func Build(report *cgjson.ProjectReport, depth int) *Graph {
+	index := cgjson.NewIndex(report)
+	graph := &Graph{Aggregated: depth > 0}
+	for _, root := range report.ProjectTreeRoots {
+		graph.Roots = append(graph.Roots, buildNode(index, root, 1, depth))
+	}
+	graph.Edges = cgjson.Aggregate(report.Edges(), func(id string) string {
+		if node, ok := index.LeafNode(id); ok {
+			return cgjson.Truncate(index.Path(node), depth)
+		}
+		return cgjson.Truncate(id, depth)
+	})
+	return graph
+}
+
+func buildNode(index *cgjson.Index, node *cgjson.ProjectNode, level, depth int) *Node {
+	visible := &Node{ID: index.Path(node), Label: node.Name, Leaf: node.IsLeaf()}
+	if level == depth {
+		return visible
+	}
+	for _, child := range node.Children {
+		visible.Children = append(visible.Children, buildNode(index, child, level+1, depth))
+	}
+	return visible
+}
+
+// style is how an edge of a given type is drawn, following the colours of the
+// visualization described in DOMAIN.md.
+type style struct {
+	Color  string
+	Dotted bool
+}
+
+func edgeStyle(edgeType cgjson.EdgeType) style {
+	switch edgeType {
+	case cgjson.Cyclic:
+		return style{Color: "#0000FF"}
+	case cgjson.FeedbackContainerLevel:
+		return style{Color: "#FF0000", Dotted: true}
+	case cgjson.FeedbackLeafLevel:
+		return style{Color: "#FF0000"}
+	default:
+		return style{Color: "#808080"}
+	}
+}
+
+// walk calls visit for every node in pre-order and after for every cluster
+// once its children have been visited.
+func walk(nodes []*Node, visit func(node *Node, depth int), after func(node *Node, depth int)) {
+	var walkNode func(node *Node, depth int)
+	walkNode = func(node *Node, depth int) {
+		visit(node, depth)
+		for _, child := range node.Children {
+			walkNode(child, depth+1)
+		}
+		if node.IsCluster() {
+			after(node, depth)
+		}
+	}
+	for _, node := range nodes {
+		walkNode(node, 0)
+	}
+}
@@ -0,0 +1,70 @@
+// Command cgexport renders a .cg.json file as a dependency graph for design
+// documents and graph tools.
+//
+// Usage:
+//
+//	cgexport [-format dot|graphml|mermaid|plantuml] [-depth n] [-o file] analysis.cg.json
+//
+// Namespaces become clusters and leaf dependencies edges, coloured like in the
+// visualization: REGULAR grey, CYCLIC blue, FEEDBACK_CONTAINER_LEVEL red
+// dotted and FEEDBACK_LEAF_LEVEL red solid. With -depth, namespaces at that
+// depth are collapsed into single nodes and the edges between them are
+// aggregated, summing their weights.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+)
+
+func main() {
+	format := flag.String("format", "dot", "output format: "+strings.Join(formats(), ", "))
+	depth := flag.Int("depth", 0, "collapse namespaces at this depth, counting the roots as 1; 0 shows all leaves")
+	output := flag.String("o", "", "write to this file instead of stdout")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: cgexport [-format %s] [-depth n] [-o file] analysis.cg.json\n", strings.Join(formats(), "|"))
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	write, known := writers[*format]
+	if flag.NArg() != 1 || !known || *depth < 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	report, err := cgjson.ReadFile(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if err := export(*output, write, Build(report, *depth)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+}
+
+// export writes graph to path, or to stdout if path is empty.
+func export(path string, write func(io.Writer, *Graph) error, graph *Graph) error {
+	if path == "" {
+		return write(os.Stdout, graph)
+	}
+	return cgjson.CreateFile(path, false, func(w io.Writer) error {
+		return write(w, graph)
+	})
+}
+
+func formats() []string {
+	names := make([]string, 0, len(writers))
+	for name := range writers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
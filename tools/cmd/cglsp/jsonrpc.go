@@ -0,0 +1,110 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+)
+
+// JSON-RPC error codes used by the server.
+const (
+	methodNotFound       = -32601
+	invalidParams        = -32602
+	serverNotInitialized = -32002
+)
+
+// Message is an incoming JSON-RPC request, notification or response. A
+// notification has no ID.
+type Message struct {
+	ID     json.RawMessage `json:"id,omitempty"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+}
+
+// ResponseError is the error of a failed request.
+type ResponseError struct {
+	Code    int    `json:"code"`
+	Message string `json:"message"`
+}
+
+func (e *ResponseError) Error() string {
+	return e.Message
+}
+
+// Conn reads and writes JSON-RPC messages framed by Content-Length headers,
+// as the Language Server Protocol specifies for stdio.
+type Conn struct {
+	reader *textproto.Reader
+	writer io.Writer
+}
+
+// NewConn creates a connection reading from r and writing to w.
+func NewConn(r io.Reader, w io.Writer) *Conn {
+	return &Conn{reader: textproto.NewReader(bufio.NewReader(r)), writer: w}
+}
+
+// Read reads the next message. It returns io.EOF when the client closed the
+// stream.
+func (c *Conn) Read() (*Message, error) {
+	header, err := c.reader.ReadMIMEHeader()
+	if err != nil {
+		if err == io.EOF && len(header) == 0 {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("reading message header: %w", err)
+	}
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil || length < 0 {
+		return nil, fmt.Errorf("invalid Content-Length %q", header.Get("Content-Length"))
+	}
+	content := make([]byte, length)
+	if _, err := io.ReadFull(c.reader.R, content); err != nil {
+		return nil, fmt.Errorf("reading message content: %w", err)
+	}
+	var message Message
+	if err := json.Unmarshal(content, &message); err != nil {
+		return nil, fmt.Errorf("decoding message: %w", err)
+	}
+	return &message, nil
+}
+
+// Reply sends the result of the request with the given ID, or err if it is
+// not nil.
+func (c *Conn) Reply(id json.RawMessage, result any, err *ResponseError) error {
+	if err != nil {
+		return c.write(struct {
+			JSONRPC string          `json:"jsonrpc"`
+			ID      json.RawMessage `json:"id"`
+			Error   *ResponseError  `json:"error"`
+		}{"2.0", id, err})
+	}
+	return c.write(struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      json.RawMessage `json:"id"`
+		Result  any             `json:"result"`
+	}{"2.0", id, result})
+}
+
+// Notify sends a notification.
+func (c *Conn) Notify(method string, params any) error {
+	return c.write(struct {
+		JSONRPC string `json:"jsonrpc"`
+		Method  string `json:"method"`
+		Params  any    `json:"params"`
+	}{"2.0", method, params})
+}
+
+func (c *Conn) write(message any) error {
+	content, err := json.Marshal(message)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintf(c.writer, "Content-Length: %d\r\n\r\n", len(content)); err != nil {
+		return err
+	}
+	_, err = c.writer.Write(content)
+	return err
+}
@@ -0,0 +1,46 @@
+// Command cglsp is a language server that shows the cyclic and
+// upward-pointing dependencies of an analysis in the editor.
+//
+// Usage:
+//
+//	cglsp [-analysis output/analysis.cg.json] [-root dir]
+//
+// The server speaks the Language Server Protocol over stdin and stdout. It
+// reads the analysis of the workspace and maps its leaves back to their files
+// via their physicalPath, relative to -root. Both paths are relative to the
+// workspace root unless absolute; -root defaults to the workspace root.
+//
+// Files whose leaves have cyclic or upward-pointing dependencies get a
+// diagnostic per dependency at the first occurrence of the leaf name:
+// FEEDBACK_LEAF_LEVEL dependencies as errors, FEEDBACK_CONTAINER_LEVEL and
+// CYCLIC ones as warnings. Hovering shows the level, fan-in and fan-out of the
+// leaf and a shortest cycle through it. The analysis is reloaded when a file
+// is opened or saved after it changed, e.g. because the analysis was run
+// again.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+)
+
+func main() {
+	analysis := flag.String("analysis", "output/analysis.cg.json", "analysis of the workspace")
+	root := flag.String("root", "", "directory the physical paths of the leaves are relative to (default the workspace root)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: cglsp [-analysis output/analysis.cg.json] [-root dir]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	server := NewServer(NewConn(os.Stdin, os.Stdout), *analysis, *root)
+	if err := server.Run(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,235 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// errExitWithoutShutdown is returned by Run if the client sent exit without
+// shutting the server down first.
+var errExitWithoutShutdown = errors.New("exit without shutdown")
+
+// Server answers the requests of a language client.
+type Server struct {
+	conn *Conn
+	// analysis and root are the analysis file and the directory of the
+	// physical paths as given on the command line, relative to the workspace
+	// root unless absolute. An empty root is the workspace root.
+	analysis string
+	root     string
+
+	initialized bool
+	shutdown    bool
+	workspace   *Workspace
+	documents   map[string]string
+	published   map[string]bool
+}
+
+// NewServer creates a server for the given analysis file and root directory.
+func NewServer(conn *Conn, analysis, root string) *Server {
+	return &Server{conn: conn, analysis: analysis, root: root, documents: map[string]string{}, published: map[string]bool{}}
+}
+
+// Run handles messages until the client sends exit or closes the connection.
+func (s *Server) Run() error {
+	for {
+		message, err := s.conn.Read()
+		if err == io.EOF {
+			return errExitWithoutShutdown
+		}
+		if err != nil {
+			return err
+		}
+		if message.Method == "exit" {
+			if !s.shutdown {
+				return errExitWithoutShutdown
+			}
+			return nil
+		}
+		if message.Method == "" {
+			continue // a response to a request the server did not send
+		}
+		result, responseErr := s.handle(message)
+		if message.ID == nil {
+			continue
+		}
+		if err := s.conn.Reply(message.ID, result, responseErr); err != nil {
+			return err
+		}
+	}
+}
+
+func (s *Server) handle(message *Message) (any, *ResponseError) {
+	if !s.initialized && message.Method != "initialize" {
+		return nil, &ResponseError{Code: serverNotInitialized, Message: "the server is not initialized"}
+	}
+	switch message.Method {
+	case "initialize":
+		var params InitializeParams
+		if err := json.Unmarshal(message.Params, &params); err != nil {
+			return nil, &ResponseError{Code: invalidParams, Message: err.Error()}
+		}
+		s.initialize(params)
+		return InitializeResult{
+			Capabilities: ServerCapabilities{TextDocumentSync: TextDocumentSyncOptions{OpenClose: true, Change: 1, Save: true}, HoverProvider: true},
+			ServerInfo:   ServerInfo{Name: "cglsp"},
+		}, nil
+	case "initialized":
+		s.reload(true)
+	case "shutdown":
+		s.shutdown = true
+	case "textDocument/didOpen":
+		var params DidOpenTextDocumentParams
+		if json.Unmarshal(message.Params, &params) == nil {
+			s.documents[params.TextDocument.URI] = params.TextDocument.Text
+			if !s.reload(false) {
+				s.publish(params.TextDocument.URI)
+			}
+		}
+	case "textDocument/didChange":
+		var params DidChangeTextDocumentParams
+		if json.Unmarshal(message.Params, &params) == nil && len(params.ContentChanges) > 0 {
+			s.documents[params.TextDocument.URI] = params.ContentChanges[len(params.ContentChanges)-1].Text
+			s.publish(params.TextDocument.URI)
+		}
+	case "textDocument/didClose":
+		var params DidCloseTextDocumentParams
+		if json.Unmarshal(message.Params, &params) == nil {
+			delete(s.documents, params.TextDocument.URI)
+		}
+	case "textDocument/didSave", "workspace/didChangeWatchedFiles":
+		s.reload(false)
+	case "textDocument/hover":
+		var params TextDocumentPositionParams
+		if err := json.Unmarshal(message.Params, &params); err != nil {
+			return nil, &ResponseError{Code: invalidParams, Message: err.Error()}
+		}
+		if s.workspace == nil {
+			return nil, nil
+		}
+		file := uriToPath(params.TextDocument.URI)
+		return s.workspace.Hover(file, s.text(params.TextDocument.URI, file), params.Position), nil
+	default:
+		if message.ID != nil {
+			return nil, &ResponseError{Code: methodNotFound, Message: fmt.Sprintf("method %q not found", message.Method)}
+		}
+	}
+	return nil, nil
+}
+
+func (s *Server) initialize(params InitializeParams) {
+	s.initialized = true
+	workspaceRoot := params.RootPath
+	switch {
+	case len(params.WorkspaceFolders) > 0:
+		workspaceRoot = uriToPath(params.WorkspaceFolders[0].URI)
+	case params.RootURI != "":
+		workspaceRoot = uriToPath(params.RootURI)
+	case workspaceRoot == "":
+		workspaceRoot, _ = os.Getwd()
+	}
+	s.analysis = resolve(workspaceRoot, s.analysis)
+	s.root = resolve(workspaceRoot, s.root)
+}
+
+// reload loads the analysis if it was not loaded yet or has changed since,
+// and then publishes the diagnostics of all files. If force is set, it loads
+// and publishes even if nothing changed, and reports a failure to the user.
+// It returns whether it published.
+func (s *Server) reload(force bool) bool {
+	if !force && s.workspace != nil && !s.workspace.Changed() {
+		return false
+	}
+	workspace, err := Load(s.analysis, s.root)
+	if err != nil {
+		if force || s.workspace != nil {
+			s.conn.Notify("window/showMessage", ShowMessageParams{Type: 2, Message: fmt.Sprintf("DependaCharta: %v", err)})
+		}
+		s.workspace = nil
+	} else {
+		s.workspace = workspace
+	}
+	s.publishAll()
+	return true
+}
+
+// publishAll publishes the diagnostics of all files of the analysis and
+// clears them for files that had diagnostics before but no longer have.
+func (s *Server) publishAll() {
+	var files []string
+	if s.workspace != nil {
+		files = s.workspace.Files()
+	}
+	current := map[string]bool{}
+	for _, file := range files {
+		uri := pathToURI(file)
+		current[uri] = true
+		s.publish(uri)
+	}
+	for uri := range s.published {
+		if !current[uri] {
+			s.publish(uri)
+		}
+	}
+}
+
+func (s *Server) publish(uri string) {
+	diagnostics := []Diagnostic{}
+	if s.workspace != nil {
+		file := uriToPath(uri)
+		diagnostics = s.workspace.Diagnostics(file, s.text(uri, file))
+	}
+	if len(diagnostics) == 0 && !s.published[uri] {
+		return
+	}
+	if len(diagnostics) == 0 {
+		delete(s.published, uri)
+	} else {
+		s.published[uri] = true
+	}
+	s.conn.Notify("textDocument/publishDiagnostics", PublishDiagnosticsParams{URI: uri, Diagnostics: diagnostics})
+}
+
+// text returns the content of the document, as last sent by the client if it
+// is open.
+func (s *Server) text(uri, file string) string {
+	if text, open := s.documents[uri]; open {
+		return text
+	}
+	content, _ := os.ReadFile(file)
+	return string(content)
+}
+
+func resolve(base, path string) string {
+	if filepath.IsAbs(path) {
+		return filepath.Clean(path)
+	}
+	return filepath.Join(base, path)
+}
+
+func uriToPath(uri string) string {
+	parsed, err := url.Parse(uri)
+	if err != nil || parsed.Scheme != "file" {
+		return uri
+	}
+	path := parsed.Path
+	if runtime.GOOS == "windows" {
+		path = strings.TrimPrefix(path, "/")
+	}
+	return filepath.Clean(filepath.FromSlash(path))
+}
+
+func pathToURI(path string) string {
+	slashed := filepath.ToSlash(path)
+	if !strings.HasPrefix(slashed, "/") {
+		slashed = "/" + slashed
+	}
+	return (&url.URL{Scheme: "file", Path: slashed}).String()
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson/cgjsontest"
+)
+
+// session runs the server in a workspace containing the layered analysis and
+// the file of app.domain.Model, sends it the messages created for the
+// workspace root followed by shutdown and exit, and returns everything it
+// sent back.
+func session(t *testing.T, create func(root string) []string) (string, []map[string]any) {
+	t.Helper()
+	root := t.TempDir()
+	os.MkdirAll(filepath.Join(root, "output"), 0o755)
+	cgjsontest.WriteLayered(t, filepath.Join(root, "output", "analysis.cg.json"))
+	os.MkdirAll(filepath.Join(root, "app", "domain"), 0o755)
+	os.WriteFile(filepath.Join(root, "app", "domain", "model.go"), []byte(model), 0o644)
+
+	var input bytes.Buffer
+	initialize := fmt.Sprintf(`{"jsonrpc":"2.0","id":1,"method":"initialize","params":{"rootUri":%q}}`, pathToURI(root))
+	messages := append([]string{initialize, `{"jsonrpc":"2.0","method":"initialized","params":{}}`}, create(root)...)
+	messages = append(messages, `{"jsonrpc":"2.0","id":99,"method":"shutdown"}`, `{"jsonrpc":"2.0","method":"exit"}`)
+	for _, message := range messages {
+		fmt.Fprintf(&input, "Content-Length: %d\r\n\r\n%s", len(message), message)
+	}
+	var output bytes.Buffer
+	if err := NewServer(NewConn(&input, &output), "output/analysis.cg.json", "").Run(); err != nil {
+		t.Fatal(err)
+	}
+
+	var replies []map[string]any
+	reader := NewConn(&output, io.Discard).reader
+	for {
+		header, err := reader.ReadMIMEHeader()
+		if err != nil {
+			break
+		}
+		var length int
+		fmt.Sscan(header.Get("Content-Length"), &length)
+		body := make([]byte, length)
+		io.ReadFull(reader.R, body)
+		var reply map[string]any
+		if err := json.Unmarshal(body, &reply); err != nil {
+			t.Fatal(err)
+		}
+		replies = append(replies, reply)
+	}
+	return root, replies
+}
+
+func TestSessionPublishesDiagnosticsAfterInitialization(t *testing.T) {
+	// when
+	root, replies := session(t, func(string) []string { return nil })
+
+	// then
+	if len(replies) != 5 {
+		t.Fatalf("got %v", replies)
+	}
+	if capabilities := replies[0]["result"].(map[string]any)["capabilities"].(map[string]any); capabilities["hoverProvider"] != true {
+		t.Errorf("got capabilities %v", capabilities)
+	}
+	published := map[string]int{}
+	for _, reply := range replies[1:4] {
+		params := reply["params"].(map[string]any)
+		published[params["uri"].(string)] = len(params["diagnostics"].([]any))
+	}
+	expected := map[string]int{
+		pathToURI(filepath.Join(root, "app", "adapter", "db.go")):        2,
+		pathToURI(filepath.Join(root, "app", "domain", "model.go")):      2,
+		pathToURI(filepath.Join(root, "app", "domain", "repository.go")): 1,
+	}
+	if !reflect.DeepEqual(published, expected) {
+		t.Errorf("got %v", published)
+	}
+	if replies[4]["id"] != 99.0 || replies[4]["result"] != nil {
+		t.Errorf("got %v", replies[4])
+	}
+}
+
+func TestSessionAnswersHoverForOpenDocument(t *testing.T) {
+	// when
+	_, replies := session(t, func(root string) []string {
+		uri := pathToURI(filepath.Join(root, "app", "domain", "model.go"))
+		return []string{
+			fmt.Sprintf(`{"jsonrpc":"2.0","method":"textDocument/didOpen","params":{"textDocument":{"uri":%q,"text":"\n\ntype Model struct{}"}}}`, uri),
+			fmt.Sprintf(`{"jsonrpc":"2.0","id":2,"method":"textDocument/hover","params":{"textDocument":{"uri":%q},"position":{"line":2,"character":6}}}`, uri),
+		}
+	})
+
+	// then
+	opened := replies[4]["params"].(map[string]any)["diagnostics"].([]any)[0].(map[string]any)
+	if line := opened["range"].(map[string]any)["start"].(map[string]any)["line"]; line != 2.0 {
+		t.Errorf("got diagnostic %v", opened)
+	}
+	hover := replies[5]["result"].(map[string]any)
+	if replies[5]["id"] != 2.0 || !strings.HasPrefix(hover["contents"].(map[string]any)["value"].(string), "**app.domain.Model**") {
+		t.Errorf("got %v", replies[5])
+	}
+}
+
+func TestSessionRejectsUnknownRequests(t *testing.T) {
+	// when
+	_, replies := session(t, func(string) []string {
+		return []string{`{"jsonrpc":"2.0","id":3,"method":"textDocument/definition","params":{}}`}
+	})
+
+	// then
+	if response := replies[4]; response["id"] != 3.0 || response["error"].(map[string]any)["code"] != float64(methodNotFound) {
+		t.Errorf("got %v", response)
+	}
+}
+
+func TestExitWithoutShutdownFails(t *testing.T) {
+	// given
+	input := bytes.NewBufferString("Content-Length: 33\r\n\r\n{\"jsonrpc\":\"2.0\",\"method\":\"exit\"}")
+
+	// when
+	err := NewServer(NewConn(input, io.Discard), "", "").Run()
+
+	// then
+	if err != errExitWithoutShutdown {
+		t.Errorf("got %v", err)
+	}
+}
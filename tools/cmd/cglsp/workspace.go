@@ -0,0 +1,243 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf16"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+)
+
+// Workspace is a loaded analysis with its leaves mapped to the files they
+// were found in.
+type Workspace struct {
+	// Path is the .cg.json file.
+	Path string
+	// Root is the directory the physical paths of the leaves are relative
+	// to.
+	Root     string
+	report   *cgjson.ProjectReport
+	index    *cgjson.Index
+	modified time.Time
+	files    map[string][]string
+	// successors, predecessors and cyclic hold the dependencies between
+	// distinct leaves; cyclic only the cyclic ones.
+	successors   map[string][]cgjson.Edge
+	predecessors map[string][]string
+	cyclic       map[string][]string
+}
+
+// Load reads the analysis at path. root is the directory the physical paths
+// are relative to, usually the analyzed directory.
+func Load(path, root string) (*Workspace, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	report, err := cgjson.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	w := &Workspace{
+		Path:         path,
+		Root:         root,
+		report:       report,
+		index:        cgjson.NewIndex(report),
+		modified:     info.ModTime(),
+		files:        map[string][]string{},
+		successors:   map[string][]cgjson.Edge{},
+		predecessors: map[string][]string{},
+		cyclic:       map[string][]string{},
+	}
+	for _, id := range report.LeafIDs() {
+		file := w.file(report.Leaves[id].PhysicalPath)
+		w.files[file] = append(w.files[file], id)
+	}
+	for edge := range report.Edges() {
+		if edge.IsSelf() {
+			continue
+		}
+		w.successors[edge.Source] = append(w.successors[edge.Source], edge)
+		w.predecessors[edge.Target] = append(w.predecessors[edge.Target], edge.Source)
+		if edge.IsCyclic {
+			w.cyclic[edge.Source] = append(w.cyclic[edge.Source], edge.Target)
+		}
+	}
+	return w, nil
+}
+
+// Changed reports whether the analysis file was modified or removed since it
+// was loaded.
+func (w *Workspace) Changed() bool {
+	info, err := os.Stat(w.Path)
+	return err != nil || !info.ModTime().Equal(w.modified)
+}
+
+// Files returns the files that contain leaves, sorted.
+func (w *Workspace) Files() []string {
+	files := make([]string, 0, len(w.files))
+	for file := range w.files {
+		files = append(files, file)
+	}
+	sort.Strings(files)
+	return files
+}
+
+// file converts a physical path, which uses backslashes if the analysis ran
+// on Windows, into a file path below the root.
+func (w *Workspace) file(physicalPath string) string {
+	return filepath.Join(w.Root, filepath.FromSlash(strings.ReplaceAll(physicalPath, `\`, "/")))
+}
+
+// Diagnostics returns a diagnostic for every cyclic or upward-pointing
+// dependency of the leaves in file, whose current content is text. It is
+// placed at the first occurrence of the name of the leaf, which usually is
+// its declaration.
+func (w *Workspace) Diagnostics(file, text string) []Diagnostic {
+	diagnostics := []Diagnostic{}
+	for _, id := range w.files[filepath.Clean(file)] {
+		declaration := declaration(text, w.report.Leaves[id].Name)
+		for _, edge := range w.successors[id] {
+			edgeType := edge.EdgeType()
+			if edgeType == cgjson.Regular {
+				continue
+			}
+			severity := SeverityWarning
+			if edgeType == cgjson.FeedbackLeafLevel {
+				severity = SeverityError
+			}
+			diagnostics = append(diagnostics, Diagnostic{
+				Range:    declaration,
+				Severity: severity,
+				Code:     string(edgeType),
+				Source:   "DependaCharta",
+				Message:  w.message(edge),
+			})
+		}
+	}
+	return diagnostics
+}
+
+func (w *Workspace) message(edge cgjson.Edge) string {
+	message := cgjson.Describe(edge)
+	if edge.IsCyclic {
+		if back := w.cyclePath(edge.Target, edge.Source); back != nil {
+			message += "\nCycle: " + strings.Join(append([]string{edge.Source}, back...), " -> ")
+		}
+	}
+	return message
+}
+
+// Hover describes the leaf of file declared at or before position: its
+// level, fan-in and fan-out, a shortest cycle through it and its upward
+// dependencies. It returns nil if the file contains no leaves.
+func (w *Workspace) Hover(file, text string, position Position) *Hover {
+	ids := w.files[filepath.Clean(file)]
+	if len(ids) == 0 {
+		return nil
+	}
+	// Take the leaf declared last before the position, or the first leaf if
+	// none is declared before it.
+	id, declared := ids[0], declaration(text, w.report.Leaves[ids[0]].Name)
+	for _, candidate := range ids[1:] {
+		candidateDeclared := declaration(text, w.report.Leaves[candidate].Name)
+		if candidateDeclared.Start.Line <= position.Line && (declared.Start.Line > position.Line || candidateDeclared.Start.Line > declared.Start.Line) {
+			id, declared = candidate, candidateDeclared
+		}
+	}
+
+	leaf := w.report.Leaves[id]
+	level := 0
+	if node, ok := w.index.LeafNode(id); ok {
+		level = node.Level
+	}
+	var content strings.Builder
+	fmt.Fprintf(&content, "**%s** (%s)\n\n", id, leaf.NodeType)
+	fmt.Fprintf(&content, "Level: %d · Fan-in: %d · Fan-out: %d\n", level, len(w.predecessors[id]), len(w.successors[id]))
+	if cycle := w.cycleThrough(id); cycle != nil {
+		fmt.Fprintf(&content, "\nCycle: %s\n", strings.Join(cycle, " → "))
+	}
+	var upward []string
+	for _, edge := range w.successors[id] {
+		if edge.IsPointingUpwards {
+			upward = append(upward, edge.Target)
+		}
+	}
+	if len(upward) > 0 {
+		fmt.Fprintf(&content, "\nUpward dependencies: %s\n", strings.Join(upward, ", "))
+	}
+
+	hover := &Hover{Contents: MarkupContent{Kind: "markdown", Value: content.String()}}
+	if declared.Start.Line == position.Line && declared.Start.Character <= position.Character && position.Character <= declared.End.Character {
+		hover.Range = &declared
+	}
+	return hover
+}
+
+// cycleThrough returns a shortest cycle through id, starting and ending with
+// it, or nil if id is not cyclic.
+func (w *Workspace) cycleThrough(id string) []string {
+	var shortest []string
+	for _, next := range w.cyclic[id] {
+		if back := w.cyclePath(next, id); back != nil && (shortest == nil || len(back) < len(shortest)) {
+			shortest = back
+		}
+	}
+	if shortest == nil {
+		return nil
+	}
+	return append([]string{id}, shortest...)
+}
+
+// cyclePath returns a shortest path from one leaf to another along cyclic
+// dependencies, including both, or nil if there is none.
+func (w *Workspace) cyclePath(from, to string) []string {
+	previous := map[string]string{from: ""}
+	queue := []string{from}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == to {
+			var path []string
+			for id := to; id != ""; id = previous[id] {
+				path = append([]string{id}, path...)
+			}
+			return path
+		}
+		for _, next := range w.cyclic[current] {
+			if _, seen := previous[next]; !seen {
+				previous[next] = current
+				queue = append(queue, next)
+			}
+		}
+	}
+	return nil
+}
+
+// declaration returns the range of the first occurrence of name as a whole
+// word in text, or the start of the text if there is none.
+func declaration(text, name string) Range {
+	if name == "" {
+		return Range{}
+	}
+	word := regexp.MustCompile(`\b` + regexp.QuoteMeta(name) + `\b`)
+	for line, content := range strings.Split(text, "\n") {
+		if location := word.FindStringIndex(content); location != nil {
+			start := utf16Length(content[:location[0]])
+			return Range{
+				Start: Position{Line: line, Character: start},
+				End:   Position{Line: line, Character: start + utf16Length(name)},
+			}
+		}
+	}
+	return Range{}
+}
+
+func utf16Length(s string) int {
+	return len(utf16.Encode([]rune(s)))
+}
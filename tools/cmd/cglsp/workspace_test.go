@@ -0,0 +1,168 @@
+package main
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson/cgjsontest"
+)
+
+const model = `package domain
+
+// Model is the domain model.
+type Model struct {
+	repository Repository
+}
+`
+
+func load(t *testing.T) *Workspace {
+	t.Helper()
+	workspace, err := Load(cgjsontest.LayeredFile(t), "/project")
+	if err != nil {
+		t.Fatal(err)
+	}
+	return workspace
+}
+
+func TestDiagnosticsAreAtTheDeclarationOfTheLeaf(t *testing.T) {
+	// given
+	workspace := load(t)
+
+	// when
+	diagnostics := workspace.Diagnostics(filepath.FromSlash("/project/app/domain/model.go"), model)
+
+	// then
+	declared := Range{Start: Position{Line: 2, Character: 3}, End: Position{Line: 2, Character: 8}}
+	if len(diagnostics) != 2 {
+		t.Fatalf("got %+v", diagnostics)
+	}
+	upward, cyclic := diagnostics[0], diagnostics[1]
+	if upward.Code != "FEEDBACK_LEAF_LEVEL" || upward.Severity != SeverityError || upward.Range != declared ||
+		upward.Message != "app.domain.Model depends on app.adapter.Db, which points upwards and creates a cycle at leaf level.\nCycle: app.domain.Model -> app.adapter.Db -> app.domain.Model" {
+		t.Errorf("got %+v", upward)
+	}
+	if cyclic.Code != "CYCLIC" || !strings.HasSuffix(cyclic.Message, "\nCycle: app.domain.Model -> app.domain.Repository -> app.domain.Model") {
+		t.Errorf("got %+v", cyclic)
+	}
+}
+
+func TestDiagnosticsReportFeedbackLeafLevelAsError(t *testing.T) {
+	// given
+	workspace := load(t)
+
+	// when
+	diagnostics := workspace.Diagnostics(filepath.FromSlash("/project/app/domain/repository.go"), "")
+
+	// then
+	if len(diagnostics) != 1 || diagnostics[0].Code != "FEEDBACK_LEAF_LEVEL" || diagnostics[0].Severity != SeverityError || diagnostics[0].Range != (Range{}) {
+		t.Errorf("got %+v", diagnostics)
+	}
+}
+
+func TestDiagnosticsReportFeedbackContainerLevelAsWarning(t *testing.T) {
+	// given
+	path := filepath.Join(t.TempDir(), "decoupled.cg.json")
+	if err := cgjson.WriteFile(path, cgjsontest.Decoupled(t)); err != nil {
+		t.Fatal(err)
+	}
+	workspace, err := Load(path, "/project")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// when
+	diagnostics := workspace.Diagnostics(filepath.FromSlash("/project/app/adapter/http.go"), "")
+
+	// then
+	expected := []Diagnostic{{
+		Severity: SeverityWarning,
+		Code:     "FEEDBACK_CONTAINER_LEVEL",
+		Source:   "DependaCharta",
+		Message:  "app.adapter.Http depends on app.domain.Repository, which points upwards in the architecture.",
+	}}
+	if !reflect.DeepEqual(diagnostics, expected) {
+		t.Errorf("got %+v", diagnostics)
+	}
+}
+
+func TestFilesWithoutDebtHaveNoDiagnostics(t *testing.T) {
+	// given
+	workspace := load(t)
+
+	// when
+	http := workspace.Diagnostics(filepath.FromSlash("/project/app/adapter/http.go"), "")
+	unknown := workspace.Diagnostics(filepath.FromSlash("/project/README.md"), "")
+
+	// then
+	if len(http) != 0 || len(unknown) != 0 {
+		t.Errorf("got %+v and %+v", http, unknown)
+	}
+}
+
+func TestHoverShowsLevelFanInFanOutAndCycle(t *testing.T) {
+	// given
+	workspace := load(t)
+
+	// when
+	hover := workspace.Hover(filepath.FromSlash("/project/app/domain/model.go"), model, Position{Line: 2, Character: 5})
+
+	// then
+	expected := "**app.domain.Model** (CLASS)\n\n" +
+		"Level: 1 · Fan-in: 2 · Fan-out: 2\n\n" +
+		"Cycle: app.domain.Model → app.adapter.Db → app.domain.Model\n\n" +
+		"Upward dependencies: app.adapter.Db\n"
+	if hover == nil || hover.Contents.Value != expected || hover.Range == nil || hover.Range.Start.Line != 2 {
+		t.Errorf("got %+v", hover)
+	}
+}
+
+func TestHoverDoesNotCountSelfDependencies(t *testing.T) {
+	// given
+	report := cgjsontest.Layered(t)
+	report.Leaves["app.adapter.Http"].Dependencies["app.adapter.Http"] = cgjson.EdgeInfo{Weight: 1, Type: "usage"}
+	node, _ := cgjson.NewIndex(report).LeafNode("app.adapter.Http")
+	node.ContainedInternalDependencies["app.adapter.Http"] = cgjson.EdgeInfo{Weight: 1, Type: "usage"}
+	path := filepath.Join(t.TempDir(), "self.cg.json")
+	if err := cgjson.WriteFile(path, report); err != nil {
+		t.Fatal(err)
+	}
+	workspace, err := Load(path, "/project")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// when
+	hover := workspace.Hover(filepath.FromSlash("/project/app/adapter/http.go"), "type Http struct{}", Position{Character: 6})
+
+	// then
+	if hover == nil || !strings.Contains(hover.Contents.Value, "Level: 1 · Fan-in: 0 · Fan-out: 1\n") {
+		t.Errorf("got %+v", hover)
+	}
+}
+
+func TestHoverOutsideOfLeafFilesIsEmpty(t *testing.T) {
+	// given
+	workspace := load(t)
+
+	// when
+	hover := workspace.Hover(filepath.FromSlash("/project/README.md"), "", Position{})
+
+	// then
+	if hover != nil {
+		t.Errorf("got %+v", hover)
+	}
+}
+
+func TestDeclarationCountsUTF16CodeUnits(t *testing.T) {
+	// when
+	declared := declaration("x\n// 𝄞 Model", "Model")
+
+	// then
+	expected := Range{Start: Position{Line: 1, Character: 6}, End: Position{Line: 1, Character: 11}}
+	if !reflect.DeepEqual(declared, expected) {
+		t.Errorf("got %+v", declared)
+	}
+}
@@ -0,0 +1,72 @@
+// Command cgmerge merges several .cg.json files, e.g. of the services of a
+// monorepo that are analyzed one by one, into a single analysis.
+//
+// Usage:
+//
+//	cgmerge [-o merged.cg.json] [prefix=]analysis.cg.json...
+//
+// A prefix such as "services.orders" moves the leaves and the project tree of
+// that analysis under the given namespace. Leaves that end up with the same id
+// are unified, dependencies on leaves of other analyses are re-resolved, and
+// cycles, levels and isPointingUpwards are recomputed across the merged graph.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+)
+
+func main() {
+	output := flag.String("o", "", "write the merged analysis to this file instead of stdout")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: cgmerge [-o merged.cg.json] [prefix=]analysis.cg.json...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	var inputs []Input
+	for _, argument := range flag.Args() {
+		prefix, path := splitArgument(argument)
+		report, err := cgjson.ReadFile(path)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		inputs = append(inputs, Input{Prefix: prefix, Report: report})
+	}
+
+	merged, summary := Merge(inputs)
+	var err error
+	if *output == "" {
+		err = cgjson.Encode(os.Stdout, merged)
+	} else {
+		err = cgjson.WriteFile(*output, merged)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	fmt.Fprintf(os.Stderr, "Merged %d analyses into %d leaves: unified %d leaves, re-resolved %d dependencies.\n",
+		len(inputs), len(merged.Leaves), len(summary.Unified), summary.Resolved)
+	for _, id := range summary.Unified {
+		fmt.Fprintf(os.Stderr, "  unified %s\n", id)
+	}
+}
+
+// splitArgument splits "prefix=path" into its parts. An "=" inside the path,
+// i.e. after a path separator, does not start a prefix.
+func splitArgument(argument string) (string, string) {
+	prefix, path, found := strings.Cut(argument, "=")
+	if !found || strings.ContainsAny(prefix, `/\`) {
+		return "", argument
+	}
+	return prefix, path
+}
@@ -0,0 +1,155 @@
+package main
+
+import (
+	"slices"
+	"strings"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+)
+
+// Input is an analysis to merge together with the dot-separated namespace
+// prefix its leaves are moved under. An empty prefix keeps the ids.
+type Input struct {
+	Prefix string
+	Report *cgjson.ProjectReport
+}
+
+// Summary describes what Merge had to reconcile.
+type Summary struct {
+	// Unified lists the merged leaf ids that more than one input contained.
+	Unified []string
+	// Resolved counts dependencies on leaves missing from their own analysis
+	// that were found in another one.
+	Resolved int
+}
+
+// Merge combines the inputs into a single analysis. Leaf ids and the project
+// tree of every input are moved under its prefix. Leaves that end up with the
+// same id are unified: the first input's information is kept and the
+// dependencies of all of them are combined. A dependency on a leaf that its
+// own analysis does not contain is re-resolved against the other inputs in
+// order, so that cross-analysis dependencies connect. Finally cycles, levels
+// and isPointingUpwards are recomputed over the merged graph.
+func Merge(inputs []Input) (*cgjson.ProjectReport, Summary) {
+	merged := &cgjson.ProjectReport{Leaves: map[string]*cgjson.LeafInformation{}}
+	var summary Summary
+	unified := map[string]bool{}
+
+	for i, input := range inputs {
+		for _, id := range input.Report.LeafIDs() {
+			leaf := input.Report.Leaves[id]
+			dependencies := make(map[string]cgjson.EdgeInfo, len(leaf.Dependencies))
+			for target, info := range leaf.Dependencies {
+				resolved, crossed := resolve(inputs, i, target)
+				if crossed {
+					summary.Resolved++
+				}
+				dependencies[resolved] = combine(dependencies[resolved], info)
+			}
+
+			newID := withPrefix(input.Prefix, id)
+			existing, exists := merged.Leaves[newID]
+			if !exists {
+				copied := *leaf
+				copied.ID = newID
+				copied.Dependencies = dependencies
+				merged.Leaves[newID] = &copied
+				continue
+			}
+			if !unified[newID] {
+				unified[newID] = true
+				summary.Unified = append(summary.Unified, newID)
+			}
+			for target, info := range dependencies {
+				existing.Dependencies[target] = combine(existing.Dependencies[target], info)
+			}
+		}
+
+		for _, root := range input.Report.ProjectTreeRoots {
+			merged.ProjectTreeRoots = mergeNode(merged.ProjectTreeRoots, underPrefix(input.Prefix, copyNode(root, input.Prefix)))
+		}
+	}
+
+	slices.Sort(summary.Unified)
+	merged.Recompute()
+	return merged, summary
+}
+
+// resolve returns the merged id of the dependency target of input i and
+// whether it was found in another input.
+func resolve(inputs []Input, i int, target string) (string, bool) {
+	if _, exists := inputs[i].Report.Leaves[target]; exists {
+		return withPrefix(inputs[i].Prefix, target), false
+	}
+	for j, other := range inputs {
+		if _, exists := other.Report.Leaves[target]; exists && j != i {
+			return withPrefix(other.Prefix, target), true
+		}
+	}
+	return withPrefix(inputs[i].Prefix, target), false
+}
+
+// combine merges two dependencies on the same target. The zero EdgeInfo acts
+// as the neutral element. isCyclic and isPointingUpwards are recomputed later.
+func combine(a, b cgjson.EdgeInfo) cgjson.EdgeInfo {
+	if a.Type == "" {
+		return b
+	}
+	types := strings.Split(a.Type, ",")
+	for _, t := range strings.Split(b.Type, ",") {
+		if !slices.Contains(types, t) {
+			types = append(types, t)
+		}
+	}
+	return cgjson.EdgeInfo{Weight: max(a.Weight, b.Weight), Type: strings.Join(types, ",")}
+}
+
+func withPrefix(prefix, id string) string {
+	if prefix == "" {
+		return id
+	}
+	return prefix + "." + id
+}
+
+// copyNode deep-copies node, moving the leaf ids under prefix.
+func copyNode(node *cgjson.ProjectNode, prefix string) *cgjson.ProjectNode {
+	copied := &cgjson.ProjectNode{Name: node.Name, Level: node.Level, Attributes: node.Attributes}
+	if node.IsLeaf() {
+		id := withPrefix(prefix, *node.LeafID)
+		copied.LeafID = &id
+	}
+	for _, child := range node.Children {
+		copied.Children = append(copied.Children, copyNode(child, prefix))
+	}
+	return copied
+}
+
+// underPrefix wraps node in one namespace per segment of prefix.
+func underPrefix(prefix string, node *cgjson.ProjectNode) *cgjson.ProjectNode {
+	if prefix == "" {
+		return node
+	}
+	segments := strings.Split(prefix, ".")
+	for i := len(segments) - 1; i >= 0; i-- {
+		node = &cgjson.ProjectNode{Name: segments[i], Children: []*cgjson.ProjectNode{node}}
+	}
+	return node
+}
+
+// mergeNode adds node to siblings. A namespace with the same name as an
+// existing namespace is merged into it, and a leaf whose id already exists is
+// dropped because the leaves were unified.
+func mergeNode(siblings []*cgjson.ProjectNode, node *cgjson.ProjectNode) []*cgjson.ProjectNode {
+	for _, sibling := range siblings {
+		switch {
+		case node.IsLeaf() && sibling.IsLeaf() && *node.LeafID == *sibling.LeafID:
+			return siblings
+		case !node.IsLeaf() && !sibling.IsLeaf() && node.Name == sibling.Name:
+			for _, child := range node.Children {
+				sibling.Children = mergeNode(sibling.Children, child)
+			}
+			return siblings
+		}
+	}
+	return append(siblings, node)
+}
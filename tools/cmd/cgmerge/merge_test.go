@@ -0,0 +1,101 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson/cgjsontest"
+)
+
+func TestMergeMovesAnalysesUnderPrefixes(t *testing.T) {
+	// given
+	inputs := []Input{{Prefix: "services.orders", Report: cgjsontest.Layered(t)}, {Prefix: "services.billing", Report: cgjsontest.Layered(t)}}
+
+	// when
+	merged, summary := Merge(inputs)
+
+	// then
+	if len(merged.Leaves) != 8 || len(summary.Unified) != 0 || summary.Resolved != 0 {
+		t.Errorf("got %d leaves, summary %+v", len(merged.Leaves), summary)
+	}
+	model := merged.Leaves["services.orders.app.domain.Model"]
+	if model == nil || !reflect.DeepEqual(model.Dependencies["services.orders.app.adapter.Db"], cgjson.EdgeInfo{IsCyclic: true, Weight: 2, Type: "usage"}) {
+		t.Errorf("got %+v", model)
+	}
+	if len(merged.ProjectTreeRoots) != 1 || len(merged.ProjectTreeRoots[0].Children) != 2 {
+		t.Errorf("expected one services root with two children, got %+v", merged.ProjectTreeRoots)
+	}
+	if problems := cgjson.Validate(merged); len(problems) > 0 {
+		t.Errorf("merged report is inconsistent: %v", problems)
+	}
+}
+
+func TestMergeUnifiesCollidingLeaves(t *testing.T) {
+	// given
+	other := cgjsontest.Layered(t)
+	other.Leaves["app.adapter.Http"].Dependencies["app.domain.Model"] = cgjson.EdgeInfo{Weight: 1, Type: "usage"}
+	inputs := []Input{{Report: cgjsontest.Layered(t)}, {Report: other}}
+
+	// when
+	merged, summary := Merge(inputs)
+
+	// then
+	expected := []string{"app.adapter.Db", "app.adapter.Http", "app.domain.Model", "app.domain.Repository"}
+	if !reflect.DeepEqual(summary.Unified, expected) || len(merged.Leaves) != 4 {
+		t.Errorf("got %+v", summary)
+	}
+	if _, exists := merged.Leaves["app.adapter.Http"].Dependencies["app.domain.Model"]; !exists {
+		t.Error("expected the dependencies of both inputs")
+	}
+	if len(merged.ProjectTreeRoots[0].ContainedLeaves) != 4 {
+		t.Errorf("expected the trees to be merged, got %v", merged.ProjectTreeRoots[0].ContainedLeaves)
+	}
+}
+
+func TestMergeResolvesDependenciesAcrossAnalysesAndRecomputesCycles(t *testing.T) {
+	// given
+	client := "client.Api"
+	clientReport := &cgjson.ProjectReport{
+		ProjectTreeRoots: []*cgjson.ProjectNode{{Name: "client", Children: []*cgjson.ProjectNode{{LeafID: &client, Name: "Api"}}}},
+		Leaves: map[string]*cgjson.LeafInformation{client: {
+			ID:           client,
+			Name:         "Api",
+			Dependencies: map[string]cgjson.EdgeInfo{"app.adapter.Http": {Weight: 1, Type: "usage"}},
+		}},
+	}
+	server := cgjsontest.Layered(t)
+	server.Leaves["app.adapter.Db"].Dependencies[client] = cgjson.EdgeInfo{Weight: 1, Type: "usage"}
+	inputs := []Input{{Prefix: "web", Report: clientReport}, {Prefix: "backend", Report: server}}
+
+	// when
+	merged, summary := Merge(inputs)
+
+	// then
+	if summary.Resolved != 2 {
+		t.Errorf("got %d resolved dependencies", summary.Resolved)
+	}
+	apiToHttp := merged.Leaves["web.client.Api"].Dependencies["backend.app.adapter.Http"]
+	dbToAPI := merged.Leaves["backend.app.adapter.Db"].Dependencies["web.client.Api"]
+	if !apiToHttp.IsCyclic || !dbToAPI.IsCyclic {
+		t.Errorf("expected a cycle across the analyses, got %+v and %+v", apiToHttp, dbToAPI)
+	}
+	if problems := cgjson.Validate(merged); len(problems) > 0 {
+		t.Errorf("merged report is inconsistent: %v", problems)
+	}
+}
+
+func TestSplitArgument(t *testing.T) {
+	cases := map[string][2]string{
+		"orders=a.cg.json":         {"orders", "a.cg.json"},
+		"a.cg.json":                {"", "a.cg.json"},
+		"out/x=y/a.cg.json":        {"", "out/x=y/a.cg.json"},
+		"svc.orders=out/a.cg.json": {"svc.orders", "out/a.cg.json"},
+	}
+	for argument, expected := range cases {
+		prefix, path := splitArgument(argument)
+		if prefix != expected[0] || path != expected[1] {
+			t.Errorf("%q: got %q, %q", argument, prefix, path)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+// Command cgmetrics computes Robert C. Martin's package design metrics for
+// the namespaces of a .cg.json file.
+//
+// Usage:
+//
+//	cgmetrics [-format csv|json] [-all] [-write out.cg.json] analysis.cg.json
+//
+// For every package, i.e. namespace that directly contains leaves, it reports
+// afferent and efferent coupling, instability, abstractness as the share of
+// INTERFACE leaves, and the distance from the main sequence, counting only the
+// leaves directly in the package. With -all every namespace is reported,
+// together with the namespaces below it. With -write the analysis is additionally written to
+// a file with the metrics as attributes of the namespace nodes.
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+)
+
+func main() {
+	format := flag.String("format", "csv", "output format: csv or json")
+	all := flag.Bool("all", false, "report every namespace including its subnamespaces, not only the leaves directly in packages")
+	write := flag.String("write", "", "write the analysis with the metrics as node attributes to this file")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: cgmetrics [-format csv|json] [-all] [-write out.cg.json] analysis.cg.json\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 || *format != "csv" && *format != "json" {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	report, err := cgjson.ReadFile(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	metrics, nodes := Compute(report, *all)
+
+	if *format == "json" {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(metrics)
+	} else {
+		err = writeCSV(os.Stdout, metrics)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	if *write != "" {
+		for i, node := range nodes {
+			if node.Attributes == nil {
+				node.Attributes = map[string]float64{}
+			}
+			for name, value := range metrics[i].Attributes() {
+				node.Attributes[name] = value
+			}
+		}
+		if err := cgjson.WriteFile(*write, report); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+}
+
+func writeCSV(w io.Writer, metrics []Metrics) error {
+	out := csv.NewWriter(w)
+	out.Write([]string{"namespace", "leaves", "interfaces", "afferentCoupling", "efferentCoupling", "instability", "abstractness", "distance"})
+	for _, m := range metrics {
+		out.Write([]string{
+			m.Namespace,
+			strconv.Itoa(m.Leaves),
+			strconv.Itoa(m.Interfaces),
+			strconv.Itoa(m.AfferentCoupling),
+			strconv.Itoa(m.EfferentCoupling),
+			formatRatio(m.Instability),
+			formatRatio(m.Abstractness),
+			formatRatio(m.Distance),
+		})
+	}
+	out.Flush()
+	return out.Error()
+}
+
+func formatRatio(value float64) string {
+	return strconv.FormatFloat(value, 'f', 3, 64)
+}
@@ -0,0 +1,124 @@
+package main
+
+import (
+	"slices"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+)
+
+// Metrics are Robert C. Martin's package design metrics of a namespace.
+type Metrics struct {
+	Namespace string `json:"namespace"`
+	// Leaves and Interfaces count the leaves of the namespace and those with
+	// nodeType INTERFACE.
+	Leaves     int `json:"leaves"`
+	Interfaces int `json:"interfaces"`
+	// AfferentCoupling (Ca) counts the leaves outside the namespace that
+	// depend on a leaf inside, EfferentCoupling (Ce) the leaves outside that a
+	// leaf inside depends on.
+	AfferentCoupling int `json:"afferentCoupling"`
+	EfferentCoupling int `json:"efferentCoupling"`
+	// Instability is Ce / (Ca + Ce), or 0 for a namespace without
+	// dependencies in either direction.
+	Instability float64 `json:"instability"`
+	// Abstractness is Interfaces / Leaves.
+	Abstractness float64 `json:"abstractness"`
+	// Distance from the main sequence is |Abstractness + Instability - 1|.
+	Distance float64 `json:"distance"`
+}
+
+// Attributes returns the metrics as the per-node attributes written back into
+// the analysis.
+func (m Metrics) Attributes() map[string]float64 {
+	return map[string]float64{
+		"afferentCoupling": float64(m.AfferentCoupling),
+		"efferentCoupling": float64(m.EfferentCoupling),
+		"instability":      m.Instability,
+		"abstractness":     m.Abstractness,
+		"distance":         m.Distance,
+	}
+}
+
+// Compute returns the metrics of the namespaces of report in tree order
+// together with their nodes. Without all, only packages are included, i.e.
+// namespaces that directly contain leaves, and their leaves are only those
+// direct children, so that a package is measured without its subpackages.
+// With all, every namespace is included and measured together with all
+// namespaces below it, as one component.
+func Compute(report *cgjson.ProjectReport, all bool) ([]Metrics, []*cgjson.ProjectNode) {
+	index := cgjson.NewIndex(report)
+	edges := slices.Collect(report.Edges())
+	var metrics []Metrics
+	var nodes []*cgjson.ProjectNode
+	for node := range report.Nodes() {
+		if node.IsLeaf() {
+			continue
+		}
+		leaves := leafChildren(node)
+		if all {
+			leaves = leavesBelow(node)
+		} else if len(leaves) == 0 {
+			continue
+		}
+		metrics = append(metrics, compute(report, edges, index.Path(node), leaves))
+		nodes = append(nodes, node)
+	}
+	return metrics, nodes
+}
+
+func compute(report *cgjson.ProjectReport, edges []cgjson.Edge, namespace string, leaves map[string]bool) Metrics {
+	m := Metrics{Namespace: namespace, Leaves: len(leaves)}
+	dependents := map[string]bool{}
+	dependencies := map[string]bool{}
+	for _, edge := range edges {
+		switch {
+		case leaves[edge.Source] && !leaves[edge.Target]:
+			dependencies[edge.Target] = true
+		case !leaves[edge.Source] && leaves[edge.Target]:
+			dependents[edge.Source] = true
+		}
+	}
+	for id := range leaves {
+		if leaf, exists := report.Leaves[id]; exists && leaf.NodeType == "INTERFACE" {
+			m.Interfaces++
+		}
+	}
+	m.AfferentCoupling = len(dependents)
+	m.EfferentCoupling = len(dependencies)
+	if coupling := m.AfferentCoupling + m.EfferentCoupling; coupling > 0 {
+		m.Instability = float64(m.EfferentCoupling) / float64(coupling)
+	}
+	if m.Leaves > 0 {
+		m.Abstractness = float64(m.Interfaces) / float64(m.Leaves)
+	}
+	m.Distance = m.Abstractness + m.Instability - 1
+	if m.Distance < 0 {
+		m.Distance = -m.Distance
+	}
+	return m
+}
+
+func leafChildren(node *cgjson.ProjectNode) map[string]bool {
+	leaves := map[string]bool{}
+	for _, child := range node.Children {
+		if child.IsLeaf() {
+			leaves[*child.LeafID] = true
+		}
+	}
+	return leaves
+}
+
+func leavesBelow(node *cgjson.ProjectNode) map[string]bool {
+	leaves := map[string]bool{}
+	var collect func(node *cgjson.ProjectNode)
+	collect = func(node *cgjson.ProjectNode) {
+		if node.IsLeaf() {
+			leaves[*node.LeafID] = true
+		}
+		for _, child := range node.Children {
+			collect(child)
+		}
+	}
+	collect(node)
+	return leaves
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson/cgjsontest"
+)
+
+func TestComputePackageMetrics(t *testing.T) {
+	// given
+	report := cgjsontest.Layered(t)
+
+	// when
+	metrics, nodes := Compute(report, false)
+
+	// then
+	expected := []Metrics{
+		{Namespace: "app.domain", Leaves: 2, Interfaces: 1, AfferentCoupling: 1, EfferentCoupling: 1, Instability: 0.5, Abstractness: 0.5, Distance: 0},
+		{Namespace: "app.adapter", Leaves: 2, AfferentCoupling: 1, EfferentCoupling: 2, Instability: 2.0 / 3, Distance: 1.0 / 3},
+	}
+	if len(metrics) != 2 || len(nodes) != 2 {
+		t.Fatalf("got %+v", metrics)
+	}
+	for i := range expected {
+		if !reflect.DeepEqual(round(metrics[i]), round(expected[i])) {
+			t.Errorf("got %+v\nwant %+v", metrics[i], expected[i])
+		}
+	}
+	if nodes[0].Name != "domain" || nodes[1].Name != "adapter" {
+		t.Errorf("got nodes %s and %s", nodes[0].Name, nodes[1].Name)
+	}
+}
+
+func TestComputePackageMetricsWithoutSubpackages(t *testing.T) {
+	// given
+	report := cgjsontest.Layered(t)
+	id := "app.Main"
+	report.Leaves[id] = &cgjson.LeafInformation{ID: id, Name: "Main", NodeType: "CLASS", Dependencies: map[string]cgjson.EdgeInfo{
+		"app.adapter.Http": {Weight: 1, Type: "usage"},
+	}}
+	app := report.ProjectTreeRoots[0]
+	app.Children = append(app.Children, &cgjson.ProjectNode{LeafID: &id, Name: "Main"})
+	report.Recompute()
+
+	// when
+	metrics, _ := Compute(report, false)
+
+	// then
+	expected := Metrics{Namespace: "app", Leaves: 1, EfferentCoupling: 1, Instability: 1}
+	if len(metrics) != 3 || !reflect.DeepEqual(metrics[0], expected) {
+		t.Errorf("got %+v", metrics)
+	}
+}
+
+func TestComputeAllNamespaces(t *testing.T) {
+	// when
+	metrics, _ := Compute(cgjsontest.Layered(t), true)
+
+	// then
+	root := metrics[0]
+	if len(metrics) != 3 || root.Namespace != "app" || root.Leaves != 4 || root.Instability != 0 || root.Distance != 0.75 {
+		t.Errorf("got %+v", metrics)
+	}
+}
+
+func TestAttributesRoundTrip(t *testing.T) {
+	// given
+	report := cgjsontest.Layered(t)
+	metrics, nodes := Compute(report, false)
+	nodes[0].Attributes = metrics[0].Attributes()
+	path := t.TempDir() + "/metrics.cg.json"
+
+	// when
+	err := cgjson.WriteFile(path, report)
+	written, readErr := cgjson.ReadFile(path)
+
+	// then
+	if err != nil || readErr != nil {
+		t.Fatal(err, readErr)
+	}
+	domain, _ := cgjson.NewIndex(written).Node("app.domain")
+	if domain.Attributes["instability"] != 0.5 || domain.Attributes["afferentCoupling"] != 1 {
+		t.Errorf("got %v", domain.Attributes)
+	}
+}
+
+func round(m Metrics) Metrics {
+	const precision = 1e9
+	m.Instability = float64(int(m.Instability*precision)) / precision
+	m.Abstractness = float64(int(m.Abstractness*precision)) / precision
+	m.Distance = float64(int(m.Distance*precision)) / precision
+	return m
+}
@@ -0,0 +1,158 @@
+// Command cgquery answers dependency questions about a .cg.json file.
+//
+// Usage:
+//
+//	cgquery [flags] analysis.cg.json <query> [arguments]
+//
+// The queries are
+//
+//	dependents <selector>          leaves that directly depend on the selection
+//	dependencies <selector>        leaves the selection directly depends on
+//	closure <selector>             leaves reachable from the selection, limited by -depth;
+//	                               with -reverse the leaves that reach it
+//	path <from> <to>               a shortest dependency path
+//	paths <from> <to>              all simple paths up to -max-length edges
+//	fan-in                         leaves ranked by fan-in, limited by -top
+//	fan-out                        leaves ranked by fan-out, limited by -top
+//	cycle <leaf>                   the strongly connected component of a leaf and a
+//	                               shortest cycle through it
+//
+// A selector is a leaf id or a namespace path, which selects all leaves below
+// it. Unambiguous suffixes such as "domain.model" work as well. Results are
+// printed as text or, with -json, as JSON. The exit code is 1 if a path or
+// cycle query finds nothing and 2 on errors.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+)
+
+// arity is the number of arguments of each query.
+var arity = map[string]int{
+	"dependents":   1,
+	"dependencies": 1,
+	"closure":      1,
+	"path":         2,
+	"paths":        2,
+	"fan-in":       0,
+	"fan-out":      0,
+	"cycle":        1,
+}
+
+func main() {
+	asJSON := flag.Bool("json", false, "print the result as JSON")
+	depth := flag.Int("depth", 0, "maximum number of steps for closure, 0 for no limit")
+	reverse := flag.Bool("reverse", false, "follow dependencies backwards for closure")
+	maxLength := flag.Int("max-length", 10, "maximum number of edges of a path for paths")
+	limit := flag.Int("limit", 100, "maximum number of paths for paths")
+	top := flag.Int("top", 20, "number of leaves for fan-in and fan-out, 0 for all")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: cgquery [flags] analysis.cg.json dependents|dependencies|closure|path|paths|fan-in|fan-out|cycle [arguments]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() < 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	query, arguments := flag.Arg(1), flag.Args()[2:]
+	if expected, known := arity[query]; !known || len(arguments) != expected {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	report, err := cgjson.ReadFile(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	graph := NewGraph(report)
+	selections := make([][]string, len(arguments))
+	for i, argument := range arguments {
+		if selections[i], err = graph.Resolve(argument); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+
+	var result any
+	var lines []string
+	found := true
+	switch query {
+	case "dependents", "dependencies":
+		ids := graph.Dependents(selections[0])
+		if query == "dependencies" {
+			ids = graph.Dependencies(selections[0])
+		}
+		result, lines = ids, ids
+	case "closure":
+		reached := graph.Closure(selections[0], *depth, *reverse)
+		result = reached
+		for _, r := range reached {
+			lines = append(lines, fmt.Sprintf("%d %s", r.Distance, r.ID))
+		}
+	case "path":
+		path := graph.ShortestPath(selections[0], selections[1])
+		found = path != nil
+		result = path
+		if found {
+			lines = []string{strings.Join(path, " -> ")}
+		}
+	case "paths":
+		paths := graph.AllPaths(selections[0], selections[1], *maxLength, *limit)
+		found = len(paths) > 0
+		result = paths
+		for _, path := range paths {
+			lines = append(lines, strings.Join(path, " -> "))
+		}
+	case "fan-in", "fan-out":
+		ranks := graph.FanIn(*top)
+		if query == "fan-out" {
+			ranks = graph.FanOut(*top)
+		}
+		result = ranks
+		for _, rank := range ranks {
+			lines = append(lines, fmt.Sprintf("%d %s", rank.Count, rank.ID))
+		}
+	case "cycle":
+		if len(selections[0]) != 1 {
+			fmt.Fprintf(os.Stderr, "%q selects %d leaves, cycle needs a single leaf\n", arguments[0], len(selections[0]))
+			os.Exit(2)
+		}
+		var membership CycleMembership
+		membership, found = graph.Cycle(selections[0][0])
+		result = membership
+		if found {
+			lines = []string{"cycle: " + strings.Join(membership.Cycle, " -> "), fmt.Sprintf("component (%d leaves):", len(membership.Component))}
+			for _, id := range membership.Component {
+				lines = append(lines, "  "+id)
+			}
+		}
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(result); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	} else {
+		for _, line := range lines {
+			fmt.Println(line)
+		}
+	}
+	if !found {
+		if !*asJSON {
+			fmt.Fprintln(os.Stderr, "nothing found")
+		}
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+)
+
+// Graph answers dependency questions about the leaves of an analysis.
+type Graph struct {
+	report       *cgjson.ProjectReport
+	index        *cgjson.Index
+	paths        []string
+	successors   map[string][]string
+	predecessors map[string][]string
+}
+
+// Reached is a leaf found by Closure with its distance from the start.
+type Reached struct {
+	ID       string `json:"id"`
+	Distance int    `json:"distance"`
+}
+
+// Rank is a leaf with its fan-in or fan-out.
+type Rank struct {
+	ID    string `json:"id"`
+	Count int    `json:"count"`
+}
+
+// CycleMembership describes the strongly connected component of a leaf and
+// one shortest cycle through it.
+type CycleMembership struct {
+	ID        string   `json:"id"`
+	Component []string `json:"component"`
+	Cycle     []string `json:"cycle"`
+}
+
+// NewGraph indexes the dependencies of report between distinct leaves.
+func NewGraph(report *cgjson.ProjectReport) *Graph {
+	graph := &Graph{
+		report:       report,
+		index:        cgjson.NewIndex(report),
+		successors:   make(map[string][]string),
+		predecessors: make(map[string][]string),
+	}
+	for node := range report.Nodes() {
+		graph.paths = append(graph.paths, graph.index.Path(node))
+	}
+	for edge := range report.Edges() {
+		if edge.IsSelf() {
+			continue
+		}
+		graph.successors[edge.Source] = append(graph.successors[edge.Source], edge.Target)
+		graph.predecessors[edge.Target] = append(graph.predecessors[edge.Target], edge.Source)
+	}
+	for _, sources := range graph.predecessors {
+		sort.Strings(sources)
+	}
+	return graph
+}
+
+// Resolve returns the ids of the leaves a selector stands for. A selector is
+// a leaf id or the path of a namespace, which stands for all leaves below it.
+// If no node has exactly this path, a unique node whose path ends with
+// "."+selector is used, so "domain.model" finds
+// "src.de.sots.cellarsandcentaurs.domain.model".
+func (g *Graph) Resolve(selector string) ([]string, error) {
+	if _, exists := g.report.Leaves[selector]; exists {
+		return []string{selector}, nil
+	}
+	if node, exists := g.index.Node(selector); exists {
+		return g.leavesBelow(node), nil
+	}
+	var matches []string
+	for _, path := range g.paths {
+		if strings.HasSuffix(path, "."+selector) && !slices.Contains(matches, path) {
+			matches = append(matches, path)
+		}
+	}
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("no leaf or namespace matches %q", selector)
+	case 1:
+		node, _ := g.index.Node(matches[0])
+		return g.leavesBelow(node), nil
+	default:
+		sort.Strings(matches)
+		return nil, fmt.Errorf("%q is ambiguous, it matches %s", selector, strings.Join(matches, ", "))
+	}
+}
+
+func (g *Graph) leavesBelow(node *cgjson.ProjectNode) []string {
+	var ids []string
+	var collect func(node *cgjson.ProjectNode)
+	collect = func(node *cgjson.ProjectNode) {
+		if node.IsLeaf() {
+			ids = append(ids, *node.LeafID)
+		}
+		for _, child := range node.Children {
+			collect(child)
+		}
+	}
+	collect(node)
+	sort.Strings(ids)
+	return ids
+}
+
+// Dependents returns the leaves outside of ids that directly depend on one of
+// them, sorted.
+func (g *Graph) Dependents(ids []string) []string {
+	return g.neighbours(ids, g.predecessors)
+}
+
+// Dependencies returns the leaves outside of ids that one of them directly
+// depends on, sorted.
+func (g *Graph) Dependencies(ids []string) []string {
+	return g.neighbours(ids, g.successors)
+}
+
+func (g *Graph) neighbours(ids []string, adjacency map[string][]string) []string {
+	members := toSet(ids)
+	found := map[string]bool{}
+	for _, id := range ids {
+		for _, neighbour := range adjacency[id] {
+			if !members[neighbour] {
+				found[neighbour] = true
+			}
+		}
+	}
+	return sortedSet(found)
+}
+
+// Closure returns the leaves reachable from ids, or reaching them if reverse
+// is set, with at most depth steps; depth 0 means no limit. The result is
+// ordered by distance and id.
+func (g *Graph) Closure(ids []string, depth int, reverse bool) []Reached {
+	adjacency := g.successors
+	if reverse {
+		adjacency = g.predecessors
+	}
+	distances := map[string]int{}
+	for _, id := range ids {
+		distances[id] = 0
+	}
+	frontier := ids
+	var reached []Reached
+	for distance := 1; len(frontier) > 0 && (depth == 0 || distance <= depth); distance++ {
+		var next []string
+		for _, id := range frontier {
+			for _, neighbour := range adjacency[id] {
+				if _, seen := distances[neighbour]; !seen {
+					distances[neighbour] = distance
+					next = append(next, neighbour)
+				}
+			}
+		}
+		sort.Strings(next)
+		for _, id := range next {
+			reached = append(reached, Reached{ID: id, Distance: distance})
+		}
+		frontier = next
+	}
+	return reached
+}
+
+// ShortestPath returns a shortest dependency path from one of from to one of
+// to, or nil if there is none.
+func (g *Graph) ShortestPath(from, to []string) []string {
+	targets := toSet(to)
+	previous := map[string]string{}
+	visited := toSet(from)
+	queue := slices.Clone(from)
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		if targets[id] {
+			path := []string{id}
+			for {
+				before, ok := previous[path[0]]
+				if !ok {
+					return path
+				}
+				path = append([]string{before}, path...)
+			}
+		}
+		for _, successor := range g.successors[id] {
+			if !visited[successor] {
+				visited[successor] = true
+				previous[successor] = id
+				queue = append(queue, successor)
+			}
+		}
+	}
+	return nil
+}
+
+// AllPaths returns the simple dependency paths from one of from to one of to
+// with at most maxLength edges, stopping after limit paths. Paths end at the
+// first leaf of to they reach.
+func (g *Graph) AllPaths(from, to []string, maxLength, limit int) [][]string {
+	targets := toSet(to)
+	var paths [][]string
+	onPath := map[string]bool{}
+	var path []string
+	var search func(id string) bool
+	search = func(id string) bool {
+		path = append(path, id)
+		onPath[id] = true
+		defer func() {
+			path = path[:len(path)-1]
+			delete(onPath, id)
+		}()
+		if targets[id] && len(path) > 1 {
+			paths = append(paths, slices.Clone(path))
+			return len(paths) < limit
+		}
+		if len(path) > maxLength {
+			return true
+		}
+		for _, successor := range g.successors[id] {
+			if !onPath[successor] && !search(successor) {
+				return false
+			}
+		}
+		return true
+	}
+	for _, id := range from {
+		if targets[id] {
+			continue
+		}
+		if !search(id) {
+			break
+		}
+	}
+	return paths
+}
+
+// FanIn ranks the leaves by the number of distinct leaves depending on them
+// and returns the top entries; top 0 returns all.
+func (g *Graph) FanIn(top int) []Rank {
+	return g.rank(g.predecessors, top)
+}
+
+// FanOut ranks the leaves by the number of distinct leaves they depend on and
+// returns the top entries; top 0 returns all.
+func (g *Graph) FanOut(top int) []Rank {
+	return g.rank(g.successors, top)
+}
+
+func (g *Graph) rank(adjacency map[string][]string, top int) []Rank {
+	ranks := make([]Rank, 0, len(g.report.Leaves))
+	for _, id := range g.report.LeafIDs() {
+		ranks = append(ranks, Rank{ID: id, Count: len(adjacency[id])})
+	}
+	sort.SliceStable(ranks, func(i, j int) bool { return ranks[i].Count > ranks[j].Count })
+	if top > 0 && len(ranks) > top {
+		ranks = ranks[:top]
+	}
+	return ranks
+}
+
+// Cycle returns the strongly connected component of the leaf id and a
+// shortest cycle through it, ending with id again. ok is false if the leaf is
+// not part of a cycle.
+func (g *Graph) Cycle(id string) (CycleMembership, bool) {
+	for _, component := range cgjson.StronglyConnectedComponents(g.successors) {
+		if !slices.Contains(component, id) {
+			continue
+		}
+		var cycle []string
+		for _, successor := range g.successors[id] {
+			if path := g.ShortestPath([]string{successor}, []string{id}); path != nil && (cycle == nil || len(path) < len(cycle)-1) {
+				cycle = append([]string{id}, path...)
+			}
+		}
+		return CycleMembership{ID: id, Component: component, Cycle: cycle}, true
+	}
+	return CycleMembership{}, false
+}
+
+func toSet(ids []string) map[string]bool {
+	set := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		set[id] = true
+	}
+	return set
+}
+
+func sortedSet(set map[string]bool) []string {
+	ids := make([]string, 0, len(set))
+	for id := range set {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+	return ids
+}
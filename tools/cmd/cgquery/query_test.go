@@ -0,0 +1,151 @@
+package main
+
+import (
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson/cgjsontest"
+)
+
+func graph(t *testing.T) *Graph {
+	t.Helper()
+	return NewGraph(cgjsontest.Layered(t))
+}
+
+func resolve(t *testing.T, g *Graph, selector string) []string {
+	t.Helper()
+	ids, err := g.Resolve(selector)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ids
+}
+
+func TestResolve(t *testing.T) {
+	// given
+	g := graph(t)
+
+	// when
+	leaf := resolve(t, g, "app.domain.Model")
+	namespace := resolve(t, g, "app.domain")
+	suffix := resolve(t, g, "adapter")
+	_, missing := g.Resolve("app.web")
+
+	// then
+	if !reflect.DeepEqual(leaf, []string{"app.domain.Model"}) ||
+		!reflect.DeepEqual(namespace, []string{"app.domain.Model", "app.domain.Repository"}) ||
+		!reflect.DeepEqual(suffix, []string{"app.adapter.Db", "app.adapter.Http"}) {
+		t.Errorf("got %v, %v, %v", leaf, namespace, suffix)
+	}
+	if missing == nil || !strings.Contains(missing.Error(), "app.web") {
+		t.Errorf("got %v", missing)
+	}
+}
+
+func TestDependentsOfNamespaceExcludeItsOwnLeaves(t *testing.T) {
+	// given
+	g := graph(t)
+
+	// when
+	dependents := g.Dependents(resolve(t, g, "app.domain"))
+
+	// then
+	if !reflect.DeepEqual(dependents, []string{"app.adapter.Db"}) {
+		t.Errorf("got %v", dependents)
+	}
+}
+
+func TestClosureIsLimitedByDepth(t *testing.T) {
+	// given
+	g := graph(t)
+
+	// when
+	closure := g.Closure([]string{"app.adapter.Http"}, 1, false)
+	reverse := g.Closure([]string{"app.adapter.Http"}, 0, true)
+
+	// then
+	if !reflect.DeepEqual(closure, []Reached{{ID: "app.adapter.Db", Distance: 1}}) || len(reverse) != 0 {
+		t.Errorf("got %v and %v", closure, reverse)
+	}
+}
+
+func TestPaths(t *testing.T) {
+	// given
+	g := graph(t)
+
+	// when
+	shortest := g.ShortestPath([]string{"app.adapter.Http"}, []string{"app.domain.Model"})
+	all := g.AllPaths([]string{"app.adapter.Http"}, []string{"app.domain.Model"}, 10, 100)
+	none := g.ShortestPath([]string{"app.domain.Model"}, []string{"app.adapter.Http"})
+
+	// then
+	if !reflect.DeepEqual(shortest, []string{"app.adapter.Http", "app.adapter.Db", "app.domain.Model"}) {
+		t.Errorf("got %v", shortest)
+	}
+	expected := [][]string{
+		{"app.adapter.Http", "app.adapter.Db", "app.domain.Model"},
+		{"app.adapter.Http", "app.adapter.Db", "app.domain.Repository", "app.domain.Model"},
+	}
+	if !reflect.DeepEqual(all, expected) {
+		t.Errorf("got %v", all)
+	}
+	if none != nil {
+		t.Errorf("got %v", none)
+	}
+}
+
+func TestFanInAndFanOut(t *testing.T) {
+	// given
+	g := graph(t)
+
+	// when
+	fanIn := g.FanIn(2)
+	fanOut := g.FanOut(1)
+
+	// then
+	if !reflect.DeepEqual(fanIn, []Rank{{ID: "app.adapter.Db", Count: 2}, {ID: "app.domain.Model", Count: 2}}) {
+		t.Errorf("got %v", fanIn)
+	}
+	if !reflect.DeepEqual(fanOut, []Rank{{ID: "app.adapter.Db", Count: 2}}) {
+		t.Errorf("got %v", fanOut)
+	}
+}
+
+func TestFanInAndFanOutIgnoreSelfDependencies(t *testing.T) {
+	// given
+	report := cgjsontest.Layered(t)
+	report.Leaves["app.adapter.Http"].Dependencies["app.adapter.Http"] = cgjson.EdgeInfo{Weight: 1, Type: "usage"}
+	g := NewGraph(report)
+
+	// when
+	fanIn := g.FanIn(0)
+	fanOut := g.FanOut(0)
+
+	// then
+	if !slices.Contains(fanIn, Rank{ID: "app.adapter.Http", Count: 0}) {
+		t.Errorf("got %v", fanIn)
+	}
+	if !slices.Contains(fanOut, Rank{ID: "app.adapter.Http", Count: 1}) {
+		t.Errorf("got %v", fanOut)
+	}
+}
+
+func TestCycle(t *testing.T) {
+	// given
+	g := graph(t)
+
+	// when
+	membership, found := g.Cycle("app.domain.Repository")
+	_, httpFound := g.Cycle("app.adapter.Http")
+
+	// then
+	if !found || httpFound {
+		t.Fatalf("got %t and %t", found, httpFound)
+	}
+	if !reflect.DeepEqual(membership.Cycle, []string{"app.domain.Repository", "app.domain.Model", "app.domain.Repository"}) || len(membership.Component) != 3 {
+		t.Errorf("got %+v", membership)
+	}
+}
@@ -0,0 +1,96 @@
+// Command cgrules checks the dependencies in a .cg.json file against
+// architecture rules, e.g. to enforce layers in CI.
+//
+// Usage:
+//
+//	cgrules [-json] [-strict] architecture.rules analysis.cg.json
+//
+// The rules file format is described in package archrules. Every violating
+// dependency is printed with the rule it breaks. The exit code is 0 if all
+// rules hold, 1 if there are violations and 2 if a file could not be read or
+// parsed. Rules that match no leaf are reported as warnings, with -strict they
+// count as violations.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/MaibornWolff/DependaCharta/tools/archrules"
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+)
+
+// violation is the JSON representation of an archrules.Violation.
+type violation struct {
+	Source   string          `json:"source"`
+	Target   string          `json:"target"`
+	EdgeType cgjson.EdgeType `json:"edgeType"`
+	Weight   int             `json:"weight"`
+	Rule     string          `json:"rule"`
+	Location string          `json:"location"`
+}
+
+func main() {
+	asJSON := flag.Bool("json", false, "print the violations as JSON")
+	strict := flag.Bool("strict", false, "treat rules that match no leaf as violations")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: cgrules [-json] [-strict] architecture.rules analysis.cg.json\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 2 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	rules, err := archrules.ReadFile(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	report, err := cgjson.ReadFile(flag.Arg(1))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+
+	violations := rules.Check(report)
+	unmatched := rules.Unmatched(report)
+	for _, rule := range unmatched {
+		fmt.Fprintf(os.Stderr, "%s: warning: %q matches no leaf\n", rule.Location(), rule.Source)
+	}
+
+	if *asJSON {
+		entries := make([]violation, 0, len(violations))
+		for _, v := range violations {
+			entries = append(entries, violation{
+				Source:   v.Source,
+				Target:   v.Target,
+				EdgeType: v.EdgeType(),
+				Weight:   v.Weight,
+				Rule:     v.Rule.String(),
+				Location: v.Rule.Location(),
+			})
+		}
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		encoder.SetEscapeHTML(false)
+		if err := encoder.Encode(entries); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	} else {
+		for _, v := range violations {
+			fmt.Println(v)
+		}
+		if len(violations) > 0 {
+			fmt.Printf("%d dependencies violate the architecture rules.\n", len(violations))
+		}
+	}
+
+	if len(violations) > 0 || *strict && len(unmatched) > 0 {
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,75 @@
+// Command cgsarif exports the cyclic and upward-pointing dependencies of a
+// .cg.json file as SARIF 2.1.0, so that they show up in code-scanning UIs and
+// SARIF viewers next to other static analysis results.
+//
+// Usage:
+//
+//	cgsarif [-rules architecture.rules] [-root dir] [-o results.sarif] analysis.cg.json
+//
+// Each result uses the edge type as rule id (FEEDBACK_LEAF_LEVEL,
+// FEEDBACK_CONTAINER_LEVEL or CYCLIC) and is located at the physicalPath of
+// the source leaf. With -rules, violations of architecture rules as checked by
+// cgrules are added as ARCHITECTURE_RULE results. -root records the absolute
+// directory the physical paths are relative to.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/MaibornWolff/DependaCharta/tools/archrules"
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+)
+
+func main() {
+	rulesFile := flag.String("rules", "", "also report violations of this architecture rules file")
+	root := flag.String("root", "", "absolute directory the physical paths of the leaves are relative to")
+	output := flag.String("o", "", "write the SARIF log to this file instead of stdout")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: cgsarif [-rules architecture.rules] [-root dir] [-o results.sarif] analysis.cg.json\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	report, err := cgjson.ReadFile(flag.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	var ruleSet *archrules.RuleSet
+	if *rulesFile != "" {
+		if ruleSet, err = archrules.ReadFile(*rulesFile); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+	}
+
+	if err := write(*output, Convert(report, ruleSet, *root)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+}
+
+// write writes log to path, or to stdout if path is empty.
+func write(path string, log Log) error {
+	if path == "" {
+		return encode(os.Stdout, log)
+	}
+	return cgjson.CreateFile(path, false, func(w io.Writer) error {
+		return encode(w, log)
+	})
+}
+
+func encode(w io.Writer, log Log) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	encoder.SetEscapeHTML(false)
+	return encoder.Encode(log)
+}
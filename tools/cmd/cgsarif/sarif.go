@@ -0,0 +1,199 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/MaibornWolff/DependaCharta/tools/archrules"
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+)
+
+// The SARIF 2.1.0 types below cover the subset of the format the export
+// needs, see https://docs.oasis-open.org/sarif/sarif/v2.1.0/sarif-v2.1.0.html.
+
+type Log struct {
+	Schema  string `json:"$schema"`
+	Version string `json:"version"`
+	Runs    []Run  `json:"runs"`
+}
+
+type Run struct {
+	Tool               Tool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]ArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Results            []Result                    `json:"results"`
+}
+
+type Tool struct {
+	Driver Driver `json:"driver"`
+}
+
+type Driver struct {
+	Name           string `json:"name"`
+	InformationURI string `json:"informationUri"`
+	Rules          []Rule `json:"rules"`
+}
+
+type Rule struct {
+	ID                   string        `json:"id"`
+	Name                 string        `json:"name"`
+	ShortDescription     Message       `json:"shortDescription"`
+	FullDescription      Message       `json:"fullDescription"`
+	DefaultConfiguration Configuration `json:"defaultConfiguration"`
+}
+
+type Configuration struct {
+	Level string `json:"level"`
+}
+
+type Message struct {
+	Text string `json:"text"`
+}
+
+type Result struct {
+	RuleID              string            `json:"ruleId"`
+	RuleIndex           int               `json:"ruleIndex"`
+	Level               string            `json:"level"`
+	Message             Message           `json:"message"`
+	Locations           []Location        `json:"locations"`
+	PartialFingerprints map[string]string `json:"partialFingerprints"`
+}
+
+type Location struct {
+	PhysicalLocation PhysicalLocation  `json:"physicalLocation"`
+	LogicalLocations []LogicalLocation `json:"logicalLocations"`
+}
+
+type PhysicalLocation struct {
+	ArtifactLocation ArtifactLocation `json:"artifactLocation"`
+}
+
+type ArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type LogicalLocation struct {
+	FullyQualifiedName string `json:"fullyQualifiedName"`
+	Kind               string `json:"kind"`
+}
+
+// srcRoot is the uriBaseId the physical paths of the leaves are relative to.
+const srcRoot = "SRCROOT"
+
+// ArchitectureRule is the id of the results for violated architecture rules.
+const ArchitectureRule = "ARCHITECTURE_RULE"
+
+// rules are the reported rules, one per edge type that indicates architectural
+// debt, see DOMAIN.md, plus one for violated architecture rules.
+var rules = []Rule{
+	{
+		ID:                   string(cgjson.FeedbackLeafLevel),
+		Name:                 "FeedbackLeafLevel",
+		ShortDescription:     Message{"Upward dependency that creates a cycle at leaf level"},
+		FullDescription:      Message{"The dependency is cyclic and points upwards: its target is on the same or a higher level than its source. It creates a cycle at class or function level and violates the architecture."},
+		DefaultConfiguration: Configuration{"error"},
+	},
+	{
+		ID:                   string(cgjson.FeedbackContainerLevel),
+		Name:                 "FeedbackContainerLevel",
+		ShortDescription:     Message{"Upward dependency between containers"},
+		FullDescription:      Message{"The dependency points upwards: its target is on the same or a higher level than its source. It may create a cycle at package or module level and violates the architecture."},
+		DefaultConfiguration: Configuration{"warning"},
+	},
+	{
+		ID:                   string(cgjson.Cyclic),
+		Name:                 "CyclicDependency",
+		ShortDescription:     Message{"Cyclic dependency"},
+		FullDescription:      Message{"The dependency is part of a cycle between leaves but follows the architectural direction."},
+		DefaultConfiguration: Configuration{"warning"},
+	},
+	{
+		ID:                   ArchitectureRule,
+		Name:                 "ArchitectureRule",
+		ShortDescription:     Message{"Dependency violates an architecture rule"},
+		FullDescription:      Message{"The dependency is forbidden by the architecture rules checked with cgrules."},
+		DefaultConfiguration: Configuration{"error"},
+	},
+}
+
+// Convert turns the cyclic and upward-pointing dependencies of report, and
+// the violations of ruleSet if it is not nil, into a SARIF log. Every result is
+// located at the physicalPath of the source leaf. If root is not empty, it is
+// recorded as the location the physical paths are relative to.
+func Convert(report *cgjson.ProjectReport, ruleSet *archrules.RuleSet, root string) Log {
+	ruleIndex := make(map[string]int, len(rules))
+	for i, rule := range rules {
+		ruleIndex[rule.ID] = i
+	}
+
+	results := []Result{}
+	add := func(ruleID string, edge cgjson.Edge, text string) {
+		rule := rules[ruleIndex[ruleID]]
+		results = append(results, Result{
+			RuleID:              rule.ID,
+			RuleIndex:           ruleIndex[ruleID],
+			Level:               rule.DefaultConfiguration.Level,
+			Message:             Message{text},
+			Locations:           []Location{location(report, edge.Source)},
+			PartialFingerprints: map[string]string{"dependency/v1": ruleID + ":" + edge.Source + "->" + edge.Target},
+		})
+	}
+
+	for edge := range report.Edges() {
+		edgeType := edge.EdgeType()
+		if edgeType == cgjson.Regular {
+			continue
+		}
+		add(string(edgeType), edge, cgjson.Describe(edge))
+	}
+	if ruleSet != nil {
+		for _, violation := range ruleSet.Check(report) {
+			add(ArchitectureRule, violation.Edge, fmt.Sprintf("%s depends on %s, which violates the rule \"%s\" (%s).",
+				violation.Source, violation.Target, violation.Rule, violation.Rule.Location()))
+		}
+	}
+
+	run := Run{
+		Tool:    Tool{Driver: Driver{Name: "DependaCharta", InformationURI: "https://github.com/MaibornWolff/DependaCharta", Rules: rules}},
+		Results: results,
+	}
+	if root != "" {
+		run.OriginalURIBaseIDs = map[string]ArtifactLocation{srcRoot: {URI: directoryURI(root)}}
+	}
+	return Log{
+		Schema:  "https://json.schemastore.org/sarif-2.1.0.json",
+		Version: "2.1.0",
+		Runs:    []Run{run},
+	}
+}
+
+func location(report *cgjson.ProjectReport, id string) Location {
+	path := ""
+	if leaf, exists := report.Leaves[id]; exists {
+		path = leaf.PhysicalPath
+	}
+	return Location{
+		PhysicalLocation: PhysicalLocation{ArtifactLocation: ArtifactLocation{URI: relativeURI(path), URIBaseID: srcRoot}},
+		LogicalLocations: []LogicalLocation{{FullyQualifiedName: id, Kind: "type"}},
+	}
+}
+
+// relativeURI converts a physical path, which uses backslashes if the
+// analysis ran on Windows, into a relative URI reference.
+func relativeURI(path string) string {
+	return (&url.URL{Path: strings.ReplaceAll(path, `\`, "/")}).String()
+}
+
+// directoryURI converts an absolute directory into a file URI ending with a
+// slash, as SARIF requires for base ids.
+func directoryURI(directory string) string {
+	path := strings.ReplaceAll(directory, `\`, "/")
+	if !strings.HasPrefix(path, "/") {
+		path = "/" + path
+	}
+	if !strings.HasSuffix(path, "/") {
+		path += "/"
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
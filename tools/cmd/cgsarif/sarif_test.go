@@ -0,0 +1,81 @@
+package main
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/MaibornWolff/DependaCharta/tools/archrules"
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson/cgjsontest"
+)
+
+func TestConvertReportsCyclicAndFeedbackEdges(t *testing.T) {
+	// given
+	report := cgjsontest.Decoupled(t)
+	report.Leaves["app.domain.Model"].PhysicalPath = `src\app\domain\Model Impl.java`
+
+	// when
+	log := Convert(report, nil, "")
+
+	// then
+	var ruleIDs []string
+	for _, result := range log.Runs[0].Results {
+		ruleIDs = append(ruleIDs, result.RuleID)
+		if rules[result.RuleIndex].ID != result.RuleID {
+			t.Errorf("rule index %d does not match %s", result.RuleIndex, result.RuleID)
+		}
+	}
+	expected := []string{"FEEDBACK_CONTAINER_LEVEL", "CYCLIC", "FEEDBACK_LEAF_LEVEL"}
+	if !reflect.DeepEqual(ruleIDs, expected) {
+		t.Errorf("got %v, want %v", ruleIDs, expected)
+	}
+	location := log.Runs[0].Results[1].Locations[0]
+	if location.PhysicalLocation.ArtifactLocation.URI != "src/app/domain/Model%20Impl.java" || location.LogicalLocations[0].FullyQualifiedName != "app.domain.Model" {
+		t.Errorf("got %+v", location)
+	}
+	if log.Runs[0].OriginalURIBaseIDs != nil {
+		t.Errorf("expected no base ids without root")
+	}
+}
+
+func TestConvertAddsArchitectureRuleViolations(t *testing.T) {
+	// given
+	report := cgjsontest.Layered(t)
+	ruleSet, err := archrules.Parse(strings.NewReader("app.adapter.** must not depend on app.domain.Model\n"), "architecture.rules")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// when
+	log := Convert(report, ruleSet, `C:\work\project`)
+
+	// then
+	results := log.Runs[0].Results
+	last := results[len(results)-1]
+	if len(results) != 6 || last.RuleID != ArchitectureRule || last.Level != "error" || !strings.Contains(last.Message.Text, "architecture.rules:1") {
+		t.Errorf("got %+v", results)
+	}
+	if uri := log.Runs[0].OriginalURIBaseIDs[srcRoot].URI; uri != "file:///C:/work/project/" {
+		t.Errorf("got base uri %s", uri)
+	}
+}
+
+func TestLogHasRequiredSARIFProperties(t *testing.T) {
+	// given
+	log := Convert(cgjsontest.Layered(t), nil, "")
+
+	// when
+	encoded, err := json.Marshal(log)
+	var decoded map[string]any
+	json.Unmarshal(encoded, &decoded)
+
+	// then
+	if err != nil || decoded["version"] != "2.1.0" || decoded["$schema"] == nil {
+		t.Errorf("got %s", encoded)
+	}
+	driver := decoded["runs"].([]any)[0].(map[string]any)["tool"].(map[string]any)["driver"].(map[string]any)
+	if driver["name"] != "DependaCharta" || len(driver["rules"].([]any)) != len(rules) {
+		t.Errorf("got driver %v", driver)
+	}
+}
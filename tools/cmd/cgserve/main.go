@@ -0,0 +1,94 @@
+// Command cgserve serves one or more .cg.json files together with the built
+// visualization on a local HTTP server, and reloads the browser whenever an
+// analysis changes.
+//
+// Usage:
+//
+//	cgserve [-addr localhost:8080] [-assets dir] [-interval 1s] (analysis.cg.json | dir)...
+//
+// Directories are searched recursively for .cg.json files and watched for new
+// and modified ones, so running the analysis into a served output directory
+// updates the open visualization. An analysis is named after its path
+// relative to the directory, with the .cg.json suffix removed and "/"
+// replaced by ".".
+//
+// The server offers:
+//
+//	/                                        the visualization from -assets
+//	/analysis/<name>.cg.json                 the file of an analysis
+//	/analysis/analyzed-project.cg.json       the most recently modified analysis
+//	/api/analyses                            the loaded analyses
+//	/api/analyses/<name>/leaves              the leaves, optionally ?namespace=<path>
+//	/api/analyses/<name>/namespaces/<path>   a namespace subtree, optionally ?depth=<n>
+//	/api/analyses/<name>/cycles              the cycles and their edges
+//	/api/events                              server-sent "reload" events
+//
+// Build the visualization with "npm run build" in the visualization directory
+// first; without assets, the root page only lists the analyses.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+)
+
+func main() {
+	addr := flag.String("addr", "localhost:8080", "address to listen on")
+	assets := flag.String("assets", "../visualization/dist/visualization/browser", "directory of the built visualization, empty to serve none")
+	interval := flag.Duration("interval", time.Second, "how often to check for new and modified analyses")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: cgserve [-addr localhost:8080] [-assets dir] [-interval 1s] (analysis.cg.json | dir)...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if *assets != "" {
+		if info, err := os.Stat(*assets); err != nil || !info.IsDir() {
+			fmt.Fprintf(os.Stderr, "warning: no visualization assets in %s, serving the API only\n", *assets)
+			*assets = ""
+		}
+	}
+
+	store := NewStore(flag.Args())
+	loaded, err := store.Scan()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	if len(loaded) == 0 && err != nil {
+		os.Exit(2)
+	}
+	fmt.Fprintf(os.Stderr, "loaded %s\n", strings.Join(loaded, ", "))
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	go store.Watch(ctx, *interval, func(loaded []string, err error) {
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		if len(loaded) > 0 {
+			fmt.Fprintf(os.Stderr, "reloaded %s\n", strings.Join(loaded, ", "))
+		}
+	})
+
+	server := &http.Server{Addr: *addr, Handler: NewServer(store, *assets), BaseContext: func(_ net.Listener) context.Context { return ctx }}
+	go func() {
+		<-ctx.Done()
+		server.Shutdown(context.Background())
+	}()
+	fmt.Fprintf(os.Stderr, "serving on http://%s/\n", *addr)
+	if err := server.ListenAndServe(); err != http.ErrServerClosed {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+}
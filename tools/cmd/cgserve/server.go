@@ -0,0 +1,384 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+)
+
+// reloadScript is injected into the index.html of the visualization. It
+// reloads the page with the new analysis whenever the server reports one.
+const reloadScript = `<script>
+new EventSource("/api/events").addEventListener("reload", function (event) {
+  var name = JSON.parse(event.data).name;
+  var file = new URLSearchParams(window.location.search).get("file");
+  if (!file || file === "/analysis/" + name + ".cg.json") {
+    window.location.reload();
+  }
+});
+</script>`
+
+// analysesPage lists the analyses if no visualization assets are configured.
+var analysesPage = template.Must(template.New("analyses").Parse(`<!doctype html>
+<title>DependaCharta</title>
+<h1>Analyses</h1>
+<ul>
+{{range .}}<li><a href="{{.URL}}">{{.Name}}</a></li>
+{{end}}</ul>
+` + reloadScript + "\n"))
+
+// keepAlive is how often an idle event stream sends a comment to keep
+// proxies from closing it.
+var keepAlive = 30 * time.Second
+
+// NewServer serves the REST API, the analyses and, if assets is not empty,
+// the built visualization from that directory.
+func NewServer(store *Store, assets string) http.Handler {
+	server := &server{store: store, assets: assets}
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /api/analyses", server.listAnalyses)
+	mux.HandleFunc("GET /api/analyses/{name}/leaves", server.listLeaves)
+	mux.HandleFunc("GET /api/analyses/{name}/namespaces/{path...}", server.namespace)
+	mux.HandleFunc("GET /api/analyses/{name}/cycles", server.cycles)
+	mux.HandleFunc("GET /api/events", server.events)
+	mux.HandleFunc("GET /analysis/{file}", server.analysisFile)
+	mux.HandleFunc("GET /{$}", server.index)
+	mux.HandleFunc("GET /index.html", server.index)
+	if assets != "" {
+		mux.Handle("GET /", http.FileServer(http.Dir(assets)))
+	}
+	return mux
+}
+
+type server struct {
+	store  *Store
+	assets string
+}
+
+// AnalysisSummary describes an analysis in the list of analyses.
+type AnalysisSummary struct {
+	Name     string    `json:"name"`
+	URL      string    `json:"url"`
+	Leaves   int       `json:"leaves"`
+	Modified time.Time `json:"modified"`
+}
+
+// LeafSummary describes a leaf.
+type LeafSummary struct {
+	ID           string `json:"id"`
+	Name         string `json:"name"`
+	Namespace    string `json:"namespace"`
+	PhysicalPath string `json:"physicalPath"`
+	NodeType     string `json:"nodeType"`
+	Language     string `json:"language"`
+	Level        int    `json:"level"`
+}
+
+// Subtree is a namespace with its descendants down to the requested depth
+// and the aggregated edges between them.
+type Subtree struct {
+	Path     string     `json:"path"`
+	Children []NodeView `json:"children"`
+	Edges    []EdgeView `json:"edges"`
+}
+
+// NodeView is a node of a Subtree. Children is only set above the requested
+// depth.
+type NodeView struct {
+	Path     string     `json:"path"`
+	Name     string     `json:"name"`
+	Level    int        `json:"level"`
+	Leaf     bool       `json:"leaf"`
+	Leaves   int        `json:"leaves"`
+	Children []NodeView `json:"children,omitempty"`
+}
+
+// EdgeView is a dependency or an aggregation of dependencies.
+type EdgeView struct {
+	Source            string          `json:"source"`
+	Target            string          `json:"target"`
+	Weight            int             `json:"weight"`
+	IsCyclic          bool            `json:"isCyclic"`
+	IsPointingUpwards bool            `json:"isPointingUpwards"`
+	EdgeType          cgjson.EdgeType `json:"edgeType"`
+}
+
+// CycleView is a strongly connected component of leaves with the cyclic
+// edges inside it.
+type CycleView struct {
+	Leaves []string   `json:"leaves"`
+	Edges  []EdgeView `json:"edges"`
+}
+
+func (s *server) listAnalyses(w http.ResponseWriter, r *http.Request) {
+	summaries := []AnalysisSummary{}
+	for _, analysis := range s.store.Analyses() {
+		summaries = append(summaries, AnalysisSummary{
+			Name:     analysis.Name,
+			URL:      analysisURL(analysis.Name),
+			Leaves:   len(analysis.Report.Leaves),
+			Modified: analysis.Modified,
+		})
+	}
+	writeJSON(w, summaries)
+}
+
+func (s *server) listLeaves(w http.ResponseWriter, r *http.Request) {
+	analysis, ok := s.analysis(w, r)
+	if !ok {
+		return
+	}
+	namespace := r.URL.Query().Get("namespace")
+	leaves := []LeafSummary{}
+	for _, id := range analysis.Report.LeafIDs() {
+		if namespace != "" && !strings.HasPrefix(id, namespace+".") {
+			continue
+		}
+		leaf := analysis.Report.Leaves[id]
+		summary := LeafSummary{
+			ID:           id,
+			Name:         leaf.Name,
+			Namespace:    analysis.Index.Namespace(id),
+			PhysicalPath: leaf.PhysicalPath,
+			NodeType:     leaf.NodeType,
+			Language:     leaf.Language,
+		}
+		if node, ok := analysis.Index.LeafNode(id); ok {
+			summary.Level = node.Level
+		}
+		leaves = append(leaves, summary)
+	}
+	writeJSON(w, leaves)
+}
+
+// namespace returns the subtree of the namespace with the given path, or of
+// the roots for an empty path. The depth parameter, 1 by default, limits how
+// many levels of descendants are included; the edges between leaves inside
+// the namespace are aggregated to the deepest included nodes.
+func (s *server) namespace(w http.ResponseWriter, r *http.Request) {
+	analysis, ok := s.analysis(w, r)
+	if !ok {
+		return
+	}
+	depth := 1
+	if value := r.URL.Query().Get("depth"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 1 {
+			http.Error(w, "depth must be a positive number", http.StatusBadRequest)
+			return
+		}
+		depth = parsed
+	}
+
+	path := r.PathValue("path")
+	children := analysis.Report.ProjectTreeRoots
+	if path != "" {
+		node, exists := analysis.Index.Node(path)
+		if !exists {
+			http.Error(w, fmt.Sprintf("namespace %q not found", path), http.StatusNotFound)
+			return
+		}
+		children = node.Children
+	}
+
+	subtree := Subtree{Path: path, Children: []NodeView{}}
+	visible := map[string]string{}
+	for _, child := range children {
+		subtree.Children = append(subtree.Children, view(analysis.Index, child, depth, visible))
+	}
+	inside := func(edge cgjson.Edge) bool {
+		_, source := visible[edge.Source]
+		_, target := visible[edge.Target]
+		return source && target
+	}
+	subtree.Edges = []EdgeView{}
+	edges := func(yield func(cgjson.Edge) bool) {
+		for edge := range analysis.Report.Edges() {
+			if inside(edge) && !yield(edge) {
+				return
+			}
+		}
+	}
+	for _, edge := range cgjson.Aggregate(edges, func(id string) string { return visible[id] }) {
+		subtree.Edges = append(subtree.Edges, edgeView(edge))
+	}
+	writeJSON(w, subtree)
+}
+
+// view converts node down to depth levels and records for every leaf below
+// it the path of the deepest included node containing it.
+func view(index *cgjson.Index, node *cgjson.ProjectNode, depth int, visible map[string]string) NodeView {
+	path := index.Path(node)
+	result := NodeView{Path: path, Name: node.Name, Level: node.Level, Leaf: node.IsLeaf(), Leaves: len(node.ContainedLeaves)}
+	if depth == 1 {
+		for _, id := range node.ContainedLeaves {
+			visible[id] = path
+		}
+		if node.IsLeaf() {
+			visible[*node.LeafID] = path
+		}
+		return result
+	}
+	for _, child := range node.Children {
+		result.Children = append(result.Children, view(index, child, depth-1, visible))
+	}
+	if node.IsLeaf() {
+		visible[*node.LeafID] = path
+	}
+	return result
+}
+
+func (s *server) cycles(w http.ResponseWriter, r *http.Request) {
+	analysis, ok := s.analysis(w, r)
+	if !ok {
+		return
+	}
+	successors := map[string][]string{}
+	var cyclic []cgjson.Edge
+	for edge := range analysis.Report.Edges() {
+		if edge.IsCyclic {
+			successors[edge.Source] = append(successors[edge.Source], edge.Target)
+			cyclic = append(cyclic, edge)
+		}
+	}
+	cycles := []CycleView{}
+	for _, component := range cgjson.StronglyConnectedComponents(successors) {
+		members := map[string]bool{}
+		for _, id := range component {
+			members[id] = true
+		}
+		cycle := CycleView{Leaves: component, Edges: []EdgeView{}}
+		for _, edge := range cyclic {
+			if members[edge.Source] && members[edge.Target] {
+				cycle.Edges = append(cycle.Edges, edgeView(edge))
+			}
+		}
+		cycles = append(cycles, cycle)
+	}
+	writeJSON(w, cycles)
+}
+
+// events streams a "reload" server-sent event whenever an analysis was
+// loaded or changed.
+func (s *server) events(w http.ResponseWriter, r *http.Request) {
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "streaming is not supported", http.StatusInternalServerError)
+		return
+	}
+	loaded, unsubscribe := s.store.Subscribe()
+	defer unsubscribe()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		case name := <-loaded:
+			data, _ := json.Marshal(map[string]string{"name": name})
+			fmt.Fprintf(w, "event: reload\ndata: %s\n\n", data)
+		}
+		flusher.Flush()
+	}
+}
+
+// analysisFile serves the .cg.json file of an analysis. analyzed-project.cg.json,
+// which the visualization loads by default, is the most recently modified
+// analysis.
+func (s *server) analysisFile(w http.ResponseWriter, r *http.Request) {
+	file := r.PathValue("file")
+	name, isAnalysis := strings.CutSuffix(file, suffix)
+	var analysis *Analysis
+	var ok bool
+	switch {
+	case file == "analyzed-project"+suffix:
+		analysis, ok = s.store.Latest()
+	case isAnalysis:
+		analysis, ok = s.store.Analysis(name)
+	}
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Cache-Control", "no-cache")
+	http.ServeFile(w, r, analysis.Path)
+}
+
+// index serves the index.html of the visualization with the reload script, or
+// a plain list of the analyses if no assets are configured.
+func (s *server) index(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.Header().Set("Cache-Control", "no-cache")
+	if s.assets == "" {
+		var links []AnalysisSummary
+		for _, analysis := range s.store.Analyses() {
+			links = append(links, AnalysisSummary{Name: analysis.Name, URL: analysisURL(analysis.Name)})
+		}
+		if err := analysesPage.Execute(w, links); err != nil {
+			fmt.Fprintln(os.Stderr, "writing index:", err)
+		}
+		return
+	}
+	content, err := os.ReadFile(filepath.Join(s.assets, "index.html"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if i := bytes.LastIndex(content, []byte("</body>")); i != -1 {
+		content = append(content[:i:i], append([]byte(reloadScript), content[i:]...)...)
+	} else {
+		content = append(content, reloadScript...)
+	}
+	w.Write(content)
+}
+
+func (s *server) analysis(w http.ResponseWriter, r *http.Request) (*Analysis, bool) {
+	name := r.PathValue("name")
+	analysis, ok := s.store.Analysis(name)
+	if !ok {
+		http.Error(w, fmt.Sprintf("analysis %q not found", name), http.StatusNotFound)
+	}
+	return analysis, ok
+}
+
+func edgeView(edge cgjson.Edge) EdgeView {
+	return EdgeView{
+		Source:            edge.Source,
+		Target:            edge.Target,
+		Weight:            edge.Weight,
+		IsCyclic:          edge.IsCyclic,
+		IsPointingUpwards: edge.IsPointingUpwards,
+		EdgeType:          edge.EdgeType(),
+	}
+}
+
+// analysisURL is the URL analysisFile serves the analysis name under.
+func analysisURL(name string) string {
+	return "/analysis/" + url.PathEscape(name+suffix)
+}
+
+func writeJSON(w http.ResponseWriter, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	encoder := json.NewEncoder(w)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(value); err != nil {
+		fmt.Fprintln(os.Stderr, "writing response:", err)
+	}
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson/cgjsontest"
+)
+
+// serve writes the layered analysis into a new output directory and serves
+// it.
+func serve(t *testing.T, assets string) (*httptest.Server, *Store, string) {
+	t.Helper()
+	dir := t.TempDir()
+	cgjsontest.WriteLayered(t, filepath.Join(dir, "analysis.cg.json"))
+	store := NewStore([]string{dir})
+	if _, err := store.Scan(); err != nil {
+		t.Fatal(err)
+	}
+	server := httptest.NewServer(NewServer(store, assets))
+	t.Cleanup(server.Close)
+	return server, store, dir
+}
+
+func get(t *testing.T, url string, value any) int {
+	t.Helper()
+	response, err := http.Get(url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	if response.StatusCode == http.StatusOK && value != nil {
+		if err := json.NewDecoder(response.Body).Decode(value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return response.StatusCode
+}
+
+func TestListsAnalysesAndLeaves(t *testing.T) {
+	// given
+	server, _, _ := serve(t, "")
+
+	// when
+	var analyses []AnalysisSummary
+	get(t, server.URL+"/api/analyses", &analyses)
+	var leaves []LeafSummary
+	get(t, server.URL+"/api/analyses/analysis/leaves?namespace=app.adapter", &leaves)
+
+	// then
+	if len(analyses) != 1 || analyses[0].Name != "analysis" || analyses[0].URL != "/analysis/analysis.cg.json" || analyses[0].Leaves != 4 {
+		t.Errorf("got analyses %+v", analyses)
+	}
+	expected := []LeafSummary{
+		{ID: "app.adapter.Db", Name: "Db", Namespace: "app.adapter", PhysicalPath: "app/adapter/db.go", NodeType: "CLASS", Language: "GO", Level: 0},
+		{ID: "app.adapter.Http", Name: "Http", Namespace: "app.adapter", PhysicalPath: "app/adapter/http.go", NodeType: "FUNCTION", Language: "GO", Level: 1},
+	}
+	if !reflect.DeepEqual(leaves, expected) {
+		t.Errorf("got leaves %+v", leaves)
+	}
+}
+
+func TestUnknownAnalysisIsNotFound(t *testing.T) {
+	// given
+	server, _, _ := serve(t, "")
+
+	// when
+	status := get(t, server.URL+"/api/analyses/missing/cycles", nil)
+
+	// then
+	if status != http.StatusNotFound {
+		t.Errorf("got status %d", status)
+	}
+}
+
+func TestNamespaceAggregatesEdgesToChildren(t *testing.T) {
+	// given
+	server, _, _ := serve(t, "")
+
+	// when
+	var subtree Subtree
+	get(t, server.URL+"/api/analyses/analysis/namespaces/app", &subtree)
+
+	// then
+	expectedChildren := []NodeView{
+		{Path: "app.domain", Name: "domain", Level: 0, Leaves: 2},
+		{Path: "app.adapter", Name: "adapter", Level: 1, Leaves: 2},
+	}
+	if !reflect.DeepEqual(subtree.Children, expectedChildren) {
+		t.Errorf("got children %+v", subtree.Children)
+	}
+	expectedEdges := []EdgeView{
+		{Source: "app.adapter", Target: "app.domain", Weight: 4, IsCyclic: true, EdgeType: cgjson.Cyclic},
+		{Source: "app.domain", Target: "app.adapter", Weight: 2, IsCyclic: true, IsPointingUpwards: true, EdgeType: cgjson.FeedbackLeafLevel},
+	}
+	if !reflect.DeepEqual(subtree.Edges, expectedEdges) {
+		t.Errorf("got edges %+v", subtree.Edges)
+	}
+}
+
+func TestNamespaceWithDepthIncludesLeafEdges(t *testing.T) {
+	// given
+	server, _, _ := serve(t, "")
+
+	// when
+	var subtree Subtree
+	get(t, server.URL+"/api/analyses/analysis/namespaces/app.domain?depth=2", &subtree)
+
+	// then
+	if len(subtree.Children) != 2 || !subtree.Children[0].Leaf {
+		t.Errorf("got children %+v", subtree.Children)
+	}
+	if len(subtree.Edges) != 2 || subtree.Edges[0].Source != "app.domain.Model" || !subtree.Edges[0].IsCyclic {
+		t.Errorf("got edges %+v", subtree.Edges)
+	}
+}
+
+func TestCyclesListsComponentsWithTheirEdges(t *testing.T) {
+	// given
+	server, _, _ := serve(t, "")
+
+	// when
+	var cycles []CycleView
+	get(t, server.URL+"/api/analyses/analysis/cycles", &cycles)
+
+	// then
+	if len(cycles) != 1 || !reflect.DeepEqual(cycles[0].Leaves, []string{"app.adapter.Db", "app.domain.Model", "app.domain.Repository"}) || len(cycles[0].Edges) != 5 {
+		t.Errorf("got cycles %+v", cycles)
+	}
+}
+
+func TestServesLatestAnalysisAsAnalyzedProject(t *testing.T) {
+	// given
+	server, store, dir := serve(t, "")
+	newer := filepath.Join(dir, "nested", "other.cg.json")
+	os.Mkdir(filepath.Dir(newer), 0o755)
+	cgjsontest.WriteLayered(t, newer)
+	os.Chtimes(newer, time.Now().Add(time.Hour), time.Now().Add(time.Hour))
+	store.Scan()
+
+	// when
+	_, ok := store.Analysis("nested.other")
+	latest, _ := store.Latest()
+	status := get(t, server.URL+"/analysis/analyzed-project.cg.json", &cgjson.ProjectReport{})
+
+	// then
+	if !ok || latest.Name != "nested.other" || status != http.StatusOK {
+		t.Errorf("got latest %s and status %d", latest.Name, status)
+	}
+}
+
+func TestScanNotifiesSubscribersAboutNewAnalyses(t *testing.T) {
+	// given
+	_, store, dir := serve(t, "")
+	loaded, unsubscribe := store.Subscribe()
+	defer unsubscribe()
+
+	// when
+	cgjsontest.WriteLayered(t, filepath.Join(dir, "second.cg.json"))
+	names, err := store.Scan()
+
+	// then
+	if err != nil || !reflect.DeepEqual(names, []string{"second"}) {
+		t.Fatalf("got %v, %v", names, err)
+	}
+	select {
+	case name := <-loaded:
+		if name != "second" {
+			t.Errorf("got %s", name)
+		}
+	default:
+		t.Error("no notification")
+	}
+}
+
+func TestScanKeepsPreviousVersionOfBrokenFile(t *testing.T) {
+	// given
+	_, store, dir := serve(t, "")
+
+	// when
+	os.WriteFile(filepath.Join(dir, "analysis.cg.json"), []byte("{"), 0o644)
+	_, err := store.Scan()
+
+	// then
+	if _, ok := store.Analysis("analysis"); err == nil || !ok {
+		t.Errorf("got %v, %v", ok, err)
+	}
+}
+
+func TestEventsStreamReloads(t *testing.T) {
+	// given
+	server, store, dir := serve(t, "")
+	response, err := http.Get(server.URL + "/api/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+
+	// when
+	cgjsontest.WriteLayered(t, filepath.Join(dir, "second.cg.json"))
+	store.Scan()
+
+	// then
+	buffer := make([]byte, 256)
+	n, _ := io.ReadAtLeast(response.Body, buffer, len("event: reload\n"))
+	if !strings.HasPrefix(string(buffer[:n]), "event: reload\ndata: {\"name\":\"second\"}") {
+		t.Errorf("got %q", buffer[:n])
+	}
+}
+
+func TestIndexInjectsReloadScript(t *testing.T) {
+	// given
+	assets := t.TempDir()
+	os.WriteFile(filepath.Join(assets, "index.html"), []byte("<html><body><app-root></app-root></body></html>"), 0o644)
+	server, _, _ := serve(t, assets)
+
+	// when
+	response, err := http.Get(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	body, _ := io.ReadAll(response.Body)
+
+	// then
+	if !strings.Contains(string(body), "<app-root></app-root><script>") || !strings.HasSuffix(string(body), "</script></body></html>") {
+		t.Errorf("got %s", body)
+	}
+}
+
+func TestIndexEscapesAnalysisNames(t *testing.T) {
+	// given
+	server, store, dir := serve(t, "")
+	cgjsontest.WriteLayered(t, filepath.Join(dir, `<b>"x"&y.cg.json`))
+	if _, err := store.Scan(); err != nil {
+		t.Fatal(err)
+	}
+
+	// when
+	response, err := http.Get(server.URL + "/")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer response.Body.Close()
+	body, _ := io.ReadAll(response.Body)
+
+	// then
+	expected := `<li><a href="/analysis/%3Cb%3E%22x%22&amp;y.cg.json">&lt;b&gt;&#34;x&#34;&amp;y</a></li>`
+	if !strings.Contains(string(body), expected) || strings.Contains(string(body), "<b>") {
+		t.Errorf("got %s", body)
+	}
+	if status := get(t, server.URL+"/analysis/%3Cb%3E%22x%22&y.cg.json", nil); status != http.StatusOK {
+		t.Errorf("got status %d for the escaped link", status)
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+)
+
+const suffix = ".cg.json"
+
+// Analysis is a loaded .cg.json file.
+type Analysis struct {
+	Name     string
+	Path     string
+	Modified time.Time
+	Report   *cgjson.ProjectReport
+	Index    *cgjson.Index
+	size     int64
+}
+
+// Store holds the analyses found in a set of files and directories and keeps
+// them up to date.
+type Store struct {
+	sources     []string
+	mu          sync.RWMutex
+	analyses    map[string]*Analysis
+	subscribers map[chan string]struct{}
+}
+
+// NewStore creates a store for the given .cg.json files and directories.
+// Directories are searched recursively. Call Scan to load the analyses.
+func NewStore(sources []string) *Store {
+	return &Store{
+		sources:     sources,
+		analyses:    map[string]*Analysis{},
+		subscribers: map[chan string]struct{}{},
+	}
+}
+
+// Scan loads new and modified analyses and forgets deleted ones. It returns
+// the names of the analyses that were loaded, and notifies the subscribers
+// about them. Files that cannot be decoded, e.g. because they are still being
+// written, are skipped and retried by the next scan. The returned error is
+// the first such problem.
+func (s *Store) Scan() ([]string, error) {
+	found := map[string]string{}
+	var firstErr error
+	for _, source := range s.sources {
+		info, err := os.Stat(source)
+		if err != nil {
+			firstErr = firstError(firstErr, err)
+			continue
+		}
+		if !info.IsDir() {
+			found[strings.TrimSuffix(filepath.Base(source), suffix)] = source
+			continue
+		}
+		filepath.WalkDir(source, func(path string, entry fs.DirEntry, err error) error {
+			if err == nil && !entry.IsDir() && strings.HasSuffix(path, suffix) {
+				relative, _ := filepath.Rel(source, path)
+				found[strings.ReplaceAll(strings.TrimSuffix(filepath.ToSlash(relative), suffix), "/", ".")] = path
+			}
+			return nil
+		})
+	}
+
+	var loaded []string
+	current := map[string]*Analysis{}
+	for _, name := range sortedNames(found) {
+		path := found[name]
+		s.mu.RLock()
+		previous := s.analyses[name]
+		s.mu.RUnlock()
+		info, err := os.Stat(path)
+		if err != nil {
+			firstErr = firstError(firstErr, err)
+			continue
+		}
+		if previous != nil && previous.Path == path && previous.Modified.Equal(info.ModTime()) && previous.size == info.Size() {
+			current[name] = previous
+			continue
+		}
+		report, err := cgjson.ReadFile(path)
+		if err != nil {
+			firstErr = firstError(firstErr, err)
+			if previous != nil {
+				current[name] = previous
+			}
+			continue
+		}
+		current[name] = &Analysis{Name: name, Path: path, Modified: info.ModTime(), Report: report, Index: cgjson.NewIndex(report), size: info.Size()}
+		loaded = append(loaded, name)
+	}
+
+	s.mu.Lock()
+	s.analyses = current
+	for _, name := range loaded {
+		for subscriber := range s.subscribers {
+			select {
+			case subscriber <- name:
+			default:
+			}
+		}
+	}
+	s.mu.Unlock()
+	return loaded, firstErr
+}
+
+// Watch scans every interval until ctx is done. report is called with the
+// result of every scan that loaded something or failed.
+func (s *Store) Watch(ctx context.Context, interval time.Duration, report func([]string, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if loaded, err := s.Scan(); len(loaded) > 0 || err != nil {
+				report(loaded, err)
+			}
+		}
+	}
+}
+
+// Subscribe returns a channel that receives the name of every analysis loaded
+// by a scan, and a function to unsubscribe.
+func (s *Store) Subscribe() (<-chan string, func()) {
+	subscriber := make(chan string, 16)
+	s.mu.Lock()
+	s.subscribers[subscriber] = struct{}{}
+	s.mu.Unlock()
+	return subscriber, func() {
+		s.mu.Lock()
+		delete(s.subscribers, subscriber)
+		s.mu.Unlock()
+	}
+}
+
+// Analysis returns the analysis with the given name.
+func (s *Store) Analysis(name string) (*Analysis, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	analysis, ok := s.analyses[name]
+	return analysis, ok
+}
+
+// Analyses returns all analyses, sorted by name.
+func (s *Store) Analyses() []*Analysis {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	analyses := make([]*Analysis, 0, len(s.analyses))
+	for _, name := range sortedNames(s.analyses) {
+		analyses = append(analyses, s.analyses[name])
+	}
+	return analyses
+}
+
+// Latest returns the most recently modified analysis.
+func (s *Store) Latest() (*Analysis, bool) {
+	var latest *Analysis
+	for _, analysis := range s.Analyses() {
+		if latest == nil || analysis.Modified.After(latest.Modified) {
+			latest = analysis
+		}
+	}
+	return latest, latest != nil
+}
+
+func sortedNames[V any](m map[string]V) []string {
+	names := make([]string, 0, len(m))
+	for name := range m {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// firstError keeps the first of several errors.
+func firstError(first, err error) error {
+	if first != nil {
+		return first
+	}
+	return err
+}
@@ -0,0 +1,89 @@
+// Command cgvalidate checks .cg.json files for internal consistency.
+//
+// Usage:
+//
+//	cgvalidate [-json] file.cg.json...
+//
+// Every problem is printed with its location in the file. The exit code is 0
+// if all files are consistent, 1 if problems were found and 2 if a file could
+// not be read.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+)
+
+type fileResult struct {
+	File     string           `json:"file"`
+	Problems []cgjson.Problem `json:"problems"`
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run validates the files named by args and returns the exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("cgvalidate", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	asJSON := flags.Bool("json", false, "print the problems as JSON")
+	flags.Usage = func() {
+		fmt.Fprintf(flags.Output(), "Usage: cgvalidate [-json] file.cg.json...\n")
+		flags.PrintDefaults()
+	}
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+	if flags.NArg() == 0 {
+		flags.Usage()
+		return 2
+	}
+
+	results := []fileResult{}
+	for _, file := range flags.Args() {
+		report, err := cgjson.ReadFile(file)
+		if err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+		problems := cgjson.Validate(report)
+		if problems == nil {
+			problems = []cgjson.Problem{}
+		}
+		results = append(results, fileResult{File: file, Problems: problems})
+	}
+
+	found := false
+	for _, result := range results {
+		found = found || len(result.Problems) > 0
+	}
+
+	if *asJSON {
+		encoder := json.NewEncoder(stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(results); err != nil {
+			fmt.Fprintln(stderr, err)
+			return 2
+		}
+	} else {
+		for _, result := range results {
+			for _, problem := range result.Problems {
+				fmt.Fprintf(stdout, "%s: %s\n", result.File, problem)
+			}
+			if len(result.Problems) == 0 {
+				fmt.Fprintf(stdout, "%s: ok\n", result.File)
+			}
+		}
+	}
+
+	if found {
+		return 1
+	}
+	return 0
+}
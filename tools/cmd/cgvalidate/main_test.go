@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson"
+	"github.com/MaibornWolff/DependaCharta/tools/cgjson/cgjsontest"
+)
+
+func TestRunAcceptsConsistentFile(t *testing.T) {
+	// given
+	path := cgjsontest.LayeredFile(t)
+	var stdout, stderr strings.Builder
+
+	// when
+	code := run([]string{path}, &stdout, &stderr)
+
+	// then
+	if code != 0 || stdout.String() != path+": ok\n" || stderr.Len() > 0 {
+		t.Errorf("got exit code %d, output %q and errors %q", code, stdout.String(), stderr.String())
+	}
+}
+
+func TestRunReportsProblemsOfInconsistentFile(t *testing.T) {
+	// given
+	report := cgjsontest.Layered(t)
+	info := report.Leaves["app.domain.Model"].Dependencies["app.adapter.Db"]
+	info.IsCyclic = false
+	report.Leaves["app.domain.Model"].Dependencies["app.adapter.Db"] = info
+	path := filepath.Join(t.TempDir(), "inconsistent.cg.json")
+	if err := cgjson.WriteFile(path, report); err != nil {
+		t.Fatal(err)
+	}
+	var stdout, stderr strings.Builder
+
+	// when
+	code := run([]string{path}, &stdout, &stderr)
+
+	// then
+	expected := path + `: projectTreeRoots[0].children[0].children[0].containedInternalDependencies["app.adapter.Db"]: differs from leaves["app.domain.Model"].dependencies["app.adapter.Db"]` + "\n" +
+		path + `: leaves["app.domain.Model"].dependencies["app.adapter.Db"].isCyclic: is false, but it is part of a cycle of 2 dependencies` + "\n"
+	if code != 1 || stdout.String() != expected || stderr.Len() > 0 {
+		t.Errorf("got exit code %d, output %q and errors %q", code, stdout.String(), stderr.String())
+	}
+}
+
+func TestRunPrintsProblemsAsJSON(t *testing.T) {
+	// given
+	valid := cgjsontest.LayeredFile(t)
+	var stdout, stderr strings.Builder
+
+	// when
+	code := run([]string{"-json", valid}, &stdout, &stderr)
+
+	// then
+	var results []fileResult
+	if err := json.Unmarshal([]byte(stdout.String()), &results); err != nil {
+		t.Fatal(err)
+	}
+	if code != 0 || len(results) != 1 || results[0].File != valid || results[0].Problems == nil || len(results[0].Problems) != 0 {
+		t.Errorf("got exit code %d and %+v", code, results)
+	}
+}
+
+func TestRunFailsForUnreadableFile(t *testing.T) {
+	// given
+	var stdout, stderr strings.Builder
+
+	// when
+	code := run([]string{filepath.Join(t.TempDir(), "missing.cg.json")}, &stdout, &stderr)
+
+	// then
+	if code != 2 || stdout.Len() > 0 || !strings.Contains(stderr.String(), "missing.cg.json") {
+		t.Errorf("got exit code %d, output %q and errors %q", code, stdout.String(), stderr.String())
+	}
+}
@@ -1,23 +1,146 @@
+//go:build ignore
+
 package main
 
 import (
+	"bufio"
 	"encoding/json"
+	"errors"
+	"flag"
 	"fmt"
+	"go/ast"
+	"go/build"
+	"go/doc"
 	"go/token"
+	"go/types"
+	"io"
+	"io/fs"
 	"log"
 	"os"
 	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
 	"strings"
+
+	"github.com/MaibornWolff/DependaCharta/tools/internal/godict"
 )
 
 type GoDictionaryData struct {
-	Keywords   []string            `json:"keywords"`
-	Builtins   []string            `json:"builtins"`
-	StdLibs    []string            `json:"stdlibs"`
-	Dictionary map[string][]string `json:"dictionary"`
+	Keywords []string `json:"keywords"`
+	Builtins []string `json:"builtins"`
+	// BuiltinKinds classifies each builtin as "type", "const" or "func".
+	BuiltinKinds map[string]string         `json:"builtinKinds"`
+	StdLibs      []string                  `json:"stdlibs"`
+	Dictionary   map[string][]string       `json:"dictionary"`
+	Symbols      map[string]godict.Symbols `json:"symbols"`
+	// ShortNames lists every stdlib package a short name can refer to.
+	// Dictionary keeps the first claimant for backwards compatibility.
+	ShortNames map[string][]string `json:"shortNames"`
+	// Ambiguities is the subset of ShortNames claimed by more than one
+	// package. These must be resolved against the file's import paths.
+	Ambiguities map[string][]string `json:"ambiguities"`
+	// Deprecations holds the "Deprecated:" notices of stdlib packages and
+	// symbols, only for packages that have at least one.
+	Deprecations map[string]Deprecations `json:"deprecations"`
+	// Platforms lists the GOOS/GOARCH pairs on which each public stdlib
+	// package has Go files. Packages that do not exist on the host, such as
+	// syscall/js, are included as well.
+	Platforms map[string][]string `json:"platforms"`
+	// Versions records the Go release that introduced each stdlib package
+	// and its symbols, read from GOROOT/api.
+	Versions map[string]PackageVersions `json:"versions"`
+}
+
+// Deprecations holds the deprecation notice of a package, if the package
+// itself is deprecated, and of its deprecated symbols. Methods are keyed as
+// Type.Method.
+type Deprecations struct {
+	Package string            `json:"package,omitempty"`
+	Symbols map[string]string `json:"symbols"`
 }
 
+// PackageVersions holds the introducing Go version of a package and of each of
+// its exported symbols. Methods are keyed as Type.Method.
+type PackageVersions struct {
+	Since   string            `json:"since"`
+	Symbols map[string]string `json:"symbols"`
+}
+
+const (
+	defaultPlatforms  = "linux/amd64,linux/arm64,darwin/amd64,darwin/arm64,windows/amd64,windows/arm64,freebsd/amd64,android/arm64,ios/arm64,js/wasm,wasip1/wasm,plan9/amd64"
+	defaultJSONFile   = "go_dictionary.json"
+	defaultKotlinFile = "../analysis/src/main/kotlin/de/maibornwolff/dependacharta/pipeline/processing/dependencies/dictionaries/GoStandardLibrary.kt"
+)
+
+var apiLinePattern = regexp.MustCompile(`^pkg ([^ ,]+)(?: \([^)]*\))?, (const|var|func|type|method) (.*)$`)
+
+var kotlinEntryPattern = regexp.MustCompile(`^\s*"([^"]+)" to Path\(listOf\(([^)]*)\)\)`)
+
 func main() {
+	format := flag.String("format", "json", "output format: json or kotlin")
+	check := flag.Bool("check", false, "compare the local toolchain with the committed dictionary files and exit non-zero on drift")
+	goroot := flag.String("goroot", "", "read the stdlib from this unpacked Go SDK instead of running `go list std`")
+	platforms := flag.String("platforms", defaultPlatforms, "comma-separated GOOS/GOARCH pairs to record stdlib package availability for")
+	jsonFile := flag.String("json-file", defaultJSONFile, "committed JSON dictionary used by -check")
+	kotlinFile := flag.String("kotlin-file", defaultKotlinFile, "committed GoStandardLibrary.kt used by -check")
+	flag.Parse()
+
+	data, err := buildDictionary(*goroot, strings.Split(*platforms, ","))
+	if err != nil {
+		log.Fatalf("Failed to get standard library packages: %v", err)
+	}
+
+	if *check {
+		drifted, err := checkDrift(os.Stdout, data, *jsonFile, *kotlinFile)
+		if err != nil {
+			log.Fatalf("Failed to check dictionary: %v", err)
+		}
+		if drifted {
+			os.Exit(1)
+		}
+		fmt.Fprintln(os.Stdout, "Go dictionary is up to date")
+		return
+	}
+
+	switch *format {
+	case "json":
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(data); err != nil {
+			log.Fatalf("Failed to encode JSON: %v", err)
+		}
+	case "kotlin":
+		if err := writeKotlin(os.Stdout, data); err != nil {
+			log.Fatalf("Failed to write Kotlin: %v", err)
+		}
+	default:
+		log.Fatalf("Unknown format %q, expected json or kotlin", *format)
+	}
+
+	fmt.Fprintf(os.Stderr, "Generated Go dictionary with:\n")
+	fmt.Fprintf(os.Stderr, "- %d keywords\n", len(data.Keywords))
+	fmt.Fprintf(os.Stderr, "- %d builtin identifiers\n", len(data.Builtins))
+	fmt.Fprintf(os.Stderr, "- %d standard library packages\n", len(data.StdLibs))
+	fmt.Fprintf(os.Stderr, "- %d total dictionary entries\n", len(data.Dictionary))
+	fmt.Fprintf(os.Stderr, "- %d exported stdlib symbols\n", countSymbols(data.Symbols))
+	fmt.Fprintf(os.Stderr, "- %d ambiguous short names\n", len(data.Ambiguities))
+	fmt.Fprintf(os.Stderr, "- %d packages with deprecations\n", len(data.Deprecations))
+}
+
+func countSymbols(symbols map[string]godict.Symbols) int {
+	count := 0
+	for _, pkgSymbols := range symbols {
+		count += len(pkgSymbols.Types) + len(pkgSymbols.Functions) + len(pkgSymbols.Constants) + len(pkgSymbols.Variables)
+	}
+	return count
+}
+
+// buildDictionary generates the dictionary data. If goroot is empty, the stdlib
+// is taken from the go binary on the PATH, otherwise the given SDK directory is
+// read directly and no go binary is needed.
+func buildDictionary(goroot string, platforms []string) (GoDictionaryData, error) {
 	data := GoDictionaryData{
 		Dictionary: make(map[string][]string),
 	}
@@ -30,35 +153,45 @@ func main() {
 	}
 	data.Keywords = keywords
 
-	builtins := []string{
-		// Types
-		"bool", "byte", "complex64", "complex128", "error", "float32", "float64",
-		"int", "int8", "int16", "int32", "int64", "rune",
-		"string", "uint", "uint8", "uint16", "uint32", "uint64", "uintptr",
-		"any", "comparable",
-		// Constants
-		"true", "false", "iota", "nil",
-		"append", "cap", "close", "complex", "copy", "delete", "imag", "len",
-		"make", "new", "panic", "print", "println", "real", "recover",
-		"clear", "max", "min",
-	}
+	builtins, builtinKinds := predeclaredIdentifiers()
 	data.Builtins = builtins
+	data.BuiltinKinds = builtinKinds
 
-	cmd := exec.Command("go", "list", "std")
-	out, err := cmd.CombinedOutput()
+	stdPkgs, err := listStdPackages(goroot)
 	if err != nil {
-		log.Fatalf("Failed to get standard library packages: %v", err)
+		return data, err
 	}
 
-	stdPkgs := strings.Fields(string(out))
 	var filteredStdPkgs []string
 	for _, pkg := range stdPkgs {
-		if !strings.Contains(pkg, "internal") && !strings.Contains(pkg, "vendor") {
+		if isPublicPackage(pkg) {
 			filteredStdPkgs = append(filteredStdPkgs, pkg)
 		}
 	}
 	data.StdLibs = filteredStdPkgs
 
+	data.ShortNames, data.Ambiguities = godict.CollectShortNames(filteredStdPkgs)
+
+	if goroot == "" {
+		out, err := exec.Command("go", "env", "GOROOT").Output()
+		if err != nil {
+			return data, err
+		}
+		goroot = strings.TrimSpace(string(out))
+	}
+	data.Symbols, data.Deprecations, err = collectSymbols(goroot, filteredStdPkgs)
+	if err != nil {
+		return data, err
+	}
+	data.Versions, err = collectVersions(filepath.Join(goroot, "api"))
+	if err != nil {
+		return data, err
+	}
+	data.Platforms, err = collectPlatforms(goroot, platforms)
+	if err != nil {
+		return data, err
+	}
+
 	for _, keyword := range keywords {
 		data.Dictionary[keyword] = []string{keyword}
 	}
@@ -72,25 +205,628 @@ func main() {
 
 		data.Dictionary[pkg] = strings.Split(internalPath, ".")
 
-		lastComponent := pkg
-		if idx := strings.LastIndex(pkg, "/"); idx != -1 {
-			lastComponent = pkg[idx+1:]
+		name := godict.ShortName(pkg)
+		if _, exists := data.Dictionary[name]; !exists {
+			data.Dictionary[name] = strings.Split(internalPath, ".")
 		}
+	}
 
-		if _, exists := data.Dictionary[lastComponent]; !exists {
-			data.Dictionary[lastComponent] = strings.Split(internalPath, ".")
+	return data, nil
+}
+
+// listStdPackages returns the import paths of the stdlib packages, sorted. If
+// goroot is empty, `go list std` is used. Otherwise goroot/src is walked with
+// go/build, skipping the cmd tree, testdata directories and the builtin
+// documentation package like the go command.
+func listStdPackages(goroot string) ([]string, error) {
+	if goroot == "" {
+		out, err := exec.Command("go", "list", "std").CombinedOutput()
+		if err != nil {
+			return nil, err
 		}
+		return strings.Fields(string(out)), nil
 	}
 
-	encoder := json.NewEncoder(os.Stdout)
-	encoder.SetIndent("", "  ")
-	if err := encoder.Encode(data); err != nil {
-		log.Fatalf("Failed to encode JSON: %v", err)
+	context := build.Default
+	context.GOROOT = goroot
+	return walkStdPackages(context)
+}
+
+// walkStdPackages returns the import paths of all packages below
+// context.GOROOT/src that have Go files for the context's platform, sorted.
+func walkStdPackages(context build.Context) ([]string, error) {
+	src := filepath.Join(context.GOROOT, "src")
+	var pkgs []string
+	err := filepath.WalkDir(src, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+		name := entry.Name()
+		if path != src && (name == "testdata" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_")) {
+			return filepath.SkipDir
+		}
+		if path == filepath.Join(src, "cmd") {
+			return filepath.SkipDir
+		}
+		// builtin only documents the predeclared identifiers and is not
+		// part of `go list std`.
+		if path == filepath.Join(src, "builtin") {
+			return nil
+		}
+		if path == src {
+			return nil
+		}
+
+		if _, err := context.ImportDir(path, 0); err != nil {
+			var noGoErr *build.NoGoError
+			if errors.As(err, &noGoErr) {
+				return nil
+			}
+			return err
+		}
+		rel, err := filepath.Rel(src, path)
+		if err != nil {
+			return err
+		}
+		pkgs = append(pkgs, filepath.ToSlash(rel))
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
+	sort.Strings(pkgs)
+	return pkgs, nil
+}
 
-	fmt.Fprintf(os.Stderr, "Generated Go dictionary with:\n")
-	fmt.Fprintf(os.Stderr, "- %d keywords\n", len(keywords))
-	fmt.Fprintf(os.Stderr, "- %d builtin identifiers\n", len(builtins))
-	fmt.Fprintf(os.Stderr, "- %d standard library packages\n", len(filteredStdPkgs))
-	fmt.Fprintf(os.Stderr, "- %d total dictionary entries\n", len(data.Dictionary))
+// cgoSupported mirrors the CgoSupported field of `go tool dist list -json`,
+// for -goroot runs on machines without a go binary. Its keys are also the
+// known GOOS/GOARCH pairs.
+var cgoSupported = map[string]bool{
+	"aix/ppc64":       true,
+	"android/386":     true,
+	"android/amd64":   true,
+	"android/arm":     true,
+	"android/arm64":   true,
+	"darwin/amd64":    true,
+	"darwin/arm64":    true,
+	"dragonfly/amd64": true,
+	"freebsd/386":     true,
+	"freebsd/amd64":   true,
+	"freebsd/arm":     true,
+	"freebsd/arm64":   true,
+	"illumos/amd64":   true,
+	"ios/amd64":       true,
+	"ios/arm64":       true,
+	"js/wasm":         false,
+	"linux/386":       true,
+	"linux/amd64":     true,
+	"linux/arm":       true,
+	"linux/arm64":     true,
+	"linux/loong64":   true,
+	"linux/mips":      true,
+	"linux/mips64":    true,
+	"linux/mips64le":  true,
+	"linux/mipsle":    true,
+	"linux/ppc64":     true,
+	"linux/ppc64le":   true,
+	"linux/riscv64":   true,
+	"linux/s390x":     true,
+	"netbsd/386":      true,
+	"netbsd/amd64":    true,
+	"netbsd/arm":      true,
+	"netbsd/arm64":    true,
+	"openbsd/386":     true,
+	"openbsd/amd64":   true,
+	"openbsd/arm":     true,
+	"openbsd/arm64":   true,
+	"openbsd/ppc64":   false,
+	"openbsd/riscv64": true,
+	"plan9/386":       false,
+	"plan9/amd64":     false,
+	"plan9/arm":       false,
+	"solaris/amd64":   true,
+	"wasip1/wasm":     false,
+	"windows/386":     true,
+	"windows/amd64":   true,
+	"windows/arm64":   true,
+}
+
+// distList returns whether cgo is supported for every GOOS/GOARCH pair, asking
+// `go tool dist list -json` of the go binary in goroot/bin, or else of the one
+// on the PATH. Only if there is neither, the cgoSupported table is used.
+func distList(goroot string) (map[string]bool, error) {
+	goBinary, err := exec.LookPath(filepath.Join(goroot, "bin", "go"))
+	if err != nil {
+		goBinary, err = exec.LookPath("go")
+	}
+	if err != nil {
+		return cgoSupported, nil
+	}
+	out, err := exec.Command(goBinary, "tool", "dist", "list", "-json").Output()
+	if err != nil {
+		return nil, err
+	}
+	var entries []struct {
+		GOOS, GOARCH string
+		CgoSupported bool
+	}
+	if err := json.Unmarshal(out, &entries); err != nil {
+		return nil, err
+	}
+	supported := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		supported[entry.GOOS+"/"+entry.GOARCH] = entry.CgoSupported
+	}
+	return supported, nil
+}
+
+// collectPlatforms evaluates the stdlib below goroot/src for every GOOS/GOARCH
+// pair and records on which of them each public package is available, see
+// godict.Available. cgo is enabled exactly where Go supports it, so that
+// runtime/cgo and plugin are reported for the platforms they can actually be
+// used on.
+func collectPlatforms(goroot string, platforms []string) (map[string][]string, error) {
+	supported, err := distList(goroot)
+	if err != nil {
+		return nil, err
+	}
+
+	availability := make(map[string][]string)
+	for _, platform := range platforms {
+		platform = strings.TrimSpace(platform)
+		goos, goarch, found := strings.Cut(platform, "/")
+		if !found || goos == "" || goarch == "" {
+			return nil, fmt.Errorf("invalid platform %q, expected GOOS/GOARCH", platform)
+		}
+		cgo, known := supported[platform]
+		if !known {
+			return nil, fmt.Errorf("unknown platform %q, see `go tool dist list`", platform)
+		}
+
+		context := build.Default
+		context.GOROOT = goroot
+		context.GOOS = goos
+		context.GOARCH = goarch
+		context.CgoEnabled = cgo
+		pkgs, err := walkStdPackages(context)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", platform, err)
+		}
+		for _, pkg := range pkgs {
+			if !isPublicPackage(pkg) {
+				continue
+			}
+			available, err := godict.Available(context, pkg)
+			if err != nil {
+				return nil, fmt.Errorf("%s on %s: %w", pkg, platform, err)
+			}
+			if available {
+				availability[pkg] = append(availability[pkg], platform)
+			}
+		}
+	}
+	return availability, nil
+}
+
+const (
+	builtinType  = "type"
+	builtinConst = "const"
+	builtinFunc  = "func"
+)
+
+// predeclaredIdentifiers returns the names in types.Universe grouped as types,
+// constants and functions, each group sorted, together with their kind. nil is
+// classified as a constant, it is the only predeclared value that is not one.
+func predeclaredIdentifiers() ([]string, map[string]string) {
+	kinds := make(map[string]string)
+	groups := make(map[string][]string)
+	for _, name := range types.Universe.Names() {
+		var kind string
+		switch types.Universe.Lookup(name).(type) {
+		case *types.TypeName:
+			kind = builtinType
+		case *types.Const, *types.Nil:
+			kind = builtinConst
+		case *types.Builtin:
+			kind = builtinFunc
+		default:
+			continue
+		}
+		kinds[name] = kind
+		groups[kind] = append(groups[kind], name)
+	}
+
+	var names []string
+	for _, kind := range []string{builtinType, builtinConst, builtinFunc} {
+		sort.Strings(groups[kind])
+		names = append(names, groups[kind]...)
+	}
+	return names, kinds
+}
+
+func isPublicPackage(pkg string) bool {
+	return !strings.Contains(pkg, "internal") && !strings.Contains(pkg, "vendor")
+}
+
+// collectVersions reads the go1*.txt files in apiDir in release order and
+// records the first release that mentions each package and symbol. An SDK
+// without an api directory yields an empty result.
+func collectVersions(apiDir string) (map[string]PackageVersions, error) {
+	versions := make(map[string]PackageVersions)
+	files, err := filepath.Glob(filepath.Join(apiDir, "go1*.txt"))
+	if err != nil {
+		return nil, err
+	}
+	if len(files) == 0 {
+		fmt.Fprintf(os.Stderr, "No API files found in %s, skipping version information\n", apiDir)
+		return versions, nil
+	}
+
+	sort.Slice(files, func(i, j int) bool {
+		return minorVersion(files[i]) < minorVersion(files[j])
+	})
+
+	for _, file := range files {
+		release := strings.TrimSuffix(filepath.Base(file), ".txt")
+		if err := readAPIFile(file, release, versions); err != nil {
+			return nil, err
+		}
+	}
+	return versions, nil
+}
+
+func minorVersion(apiFile string) int {
+	release := strings.TrimSuffix(filepath.Base(apiFile), ".txt")
+	minor, err := strconv.Atoi(strings.TrimPrefix(release, "go1."))
+	if err != nil {
+		return 0
+	}
+	return minor
+}
+
+func readAPIFile(path, release string, versions map[string]PackageVersions) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		match := apiLinePattern.FindStringSubmatch(scanner.Text())
+		if match == nil || !isPublicPackage(match[1]) {
+			continue
+		}
+		pkg, kind, rest := match[1], match[2], match[3]
+
+		pkgVersions, exists := versions[pkg]
+		if !exists {
+			pkgVersions = PackageVersions{Since: release, Symbols: make(map[string]string)}
+			versions[pkg] = pkgVersions
+		}
+
+		symbol := apiSymbolName(kind, rest)
+		if _, known := pkgVersions.Symbols[symbol]; !known {
+			pkgVersions.Symbols[symbol] = release
+		}
+	}
+	return scanner.Err()
+}
+
+// apiSymbolName extracts the symbol from the remainder of an API line, e.g.
+// "NewRequest(string, string, io.Reader) (*Request, error)" for a func or
+// "(*Client) Do(*Request) (*Response, error)" for a method.
+func apiSymbolName(kind, rest string) string {
+	if kind == "method" {
+		receiver, method, _ := strings.Cut(rest, ") ")
+		receiver = strings.TrimLeft(receiver, "(*")
+		return identifier(receiver) + "." + identifier(method)
+	}
+	return identifier(rest)
+}
+
+func identifier(s string) string {
+	if end := strings.IndexAny(s, " ([,"); end != -1 {
+		return s[:end]
+	}
+	return s
+}
+
+// collectSymbols parses the sources of every package below goroot/src for the
+// host platform and returns its exported symbols keyed by import path, along
+// with the deprecation notices of the packages that have any. Packages without
+// buildable Go files on this platform get an empty symbol entry.
+func collectSymbols(goroot string, pkgs []string) (map[string]godict.Symbols, map[string]Deprecations, error) {
+	symbols := make(map[string]godict.Symbols, len(pkgs))
+	deprecations := make(map[string]Deprecations)
+	for _, pkg := range pkgs {
+		docPkg, err := godict.ParsePackageDoc(filepath.Join(goroot, "src", filepath.FromSlash(pkg)), pkg)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", pkg, err)
+		}
+		symbols[pkg] = godict.PackageSymbols(docPkg)
+		if pkgDeprecations := packageDeprecations(docPkg); pkgDeprecations.Package != "" || len(pkgDeprecations.Symbols) > 0 {
+			deprecations[pkg] = pkgDeprecations
+		}
+	}
+	return symbols, deprecations, nil
+}
+
+// packageDeprecations collects the "Deprecated:" paragraphs of the package
+// doc and of all exported symbols. A paragraph on a const or var group applies
+// to every name in it unless the individual spec carries its own.
+func packageDeprecations(docPkg *doc.Package) Deprecations {
+	deprecations := Deprecations{Symbols: make(map[string]string)}
+	if docPkg == nil {
+		return deprecations
+	}
+	deprecations.Package = deprecationNotice(docPkg.Doc)
+
+	addValues := func(values []*doc.Value) {
+		for _, value := range values {
+			groupNotice := deprecationNotice(value.Doc)
+			for _, spec := range value.Decl.Specs {
+				valueSpec := spec.(*ast.ValueSpec)
+				notice := groupNotice
+				if valueSpec.Doc != nil {
+					if specNotice := deprecationNotice(valueSpec.Doc.Text()); specNotice != "" {
+						notice = specNotice
+					}
+				}
+				for _, name := range valueSpec.Names {
+					if notice != "" && name.IsExported() {
+						deprecations.Symbols[name.Name] = notice
+					}
+				}
+			}
+		}
+	}
+	addFuncs := func(prefix string, funcs []*doc.Func) {
+		for _, function := range funcs {
+			if notice := deprecationNotice(function.Doc); notice != "" {
+				deprecations.Symbols[prefix+function.Name] = notice
+			}
+		}
+	}
+
+	addValues(docPkg.Consts)
+	addValues(docPkg.Vars)
+	addFuncs("", docPkg.Funcs)
+	for _, docType := range docPkg.Types {
+		if notice := deprecationNotice(docType.Doc); notice != "" {
+			deprecations.Symbols[docType.Name] = notice
+		}
+		addValues(docType.Consts)
+		addValues(docType.Vars)
+		addFuncs("", docType.Funcs)
+		addFuncs(docType.Name+".", docType.Methods)
+	}
+	return deprecations
+}
+
+// deprecationNotice returns the paragraph of a doc comment that starts with
+// "Deprecated:", joined into a single line, or an empty string.
+func deprecationNotice(text string) string {
+	for _, paragraph := range strings.Split(text, "\n\n") {
+		paragraph = strings.TrimSpace(paragraph)
+		if strings.HasPrefix(paragraph, "Deprecated:") {
+			return strings.Join(strings.Fields(paragraph), " ")
+		}
+	}
+	return ""
+}
+
+// checkDrift compares the freshly generated data with the committed JSON and
+// Kotlin dictionaries and reports every added and removed keyword, builtin and
+// stdlib package. It returns true if either file is out of date.
+func checkDrift(w io.Writer, data GoDictionaryData, jsonFile, kotlinFile string) (bool, error) {
+	committedJSON, err := readJSONDictionary(jsonFile)
+	if err != nil {
+		return false, err
+	}
+	committedKotlin, err := readKotlinDictionary(kotlinFile, data)
+	if err != nil {
+		return false, err
+	}
+
+	jsonDrifted := reportDrift(w, jsonFile, data, committedJSON)
+	kotlinDrifted := reportDrift(w, kotlinFile, data, committedKotlin)
+	return jsonDrifted || kotlinDrifted, nil
+}
+
+func readJSONDictionary(path string) (GoDictionaryData, error) {
+	var data GoDictionaryData
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return data, err
+	}
+	if err := json.Unmarshal(content, &data); err != nil {
+		return data, fmt.Errorf("%s: %w", path, err)
+	}
+	return data, nil
+}
+
+// readKotlinDictionary parses the entries of GoStandardLibrary.kt. The Kotlin
+// map does not record categories, so entries are classified by their Path:
+// keywords by name, packages as entries whose key is their path joined with
+// slashes and short names as the other multi-element paths. A single-element
+// path is a package only if the generated reference still has it, and a
+// builtin otherwise, so that builtins the toolchain no longer knows are
+// reported as removed builtins.
+func readKotlinDictionary(path string, reference GoDictionaryData) (GoDictionaryData, error) {
+	data := GoDictionaryData{
+		Dictionary: make(map[string][]string),
+	}
+	file, err := os.Open(path)
+	if err != nil {
+		return data, err
+	}
+	defer file.Close()
+
+	keywords := toSet(reference.Keywords)
+	stdLibs := toSet(reference.StdLibs)
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		match := kotlinEntryPattern.FindStringSubmatch(scanner.Text())
+		if match == nil {
+			continue
+		}
+		key := match[1]
+		var parts []string
+		for _, part := range strings.Split(match[2], ",") {
+			parts = append(parts, strings.Trim(strings.TrimSpace(part), `"`))
+		}
+		data.Dictionary[key] = parts
+
+		switch {
+		case token.IsKeyword(key) || keywords[key]:
+			data.Keywords = append(data.Keywords, key)
+		case len(parts) == 1 && !stdLibs[key]:
+			data.Builtins = append(data.Builtins, key)
+		case strings.Join(parts, "/") == key:
+			data.StdLibs = append(data.StdLibs, key)
+		}
+	}
+	return data, scanner.Err()
+}
+
+func reportDrift(w io.Writer, source string, generated, committed GoDictionaryData) bool {
+	sections := []struct {
+		name      string
+		generated []string
+		committed []string
+	}{
+		{"stdlib package", generated.StdLibs, committed.StdLibs},
+		{"keyword", generated.Keywords, committed.Keywords},
+		{"builtin", generated.Builtins, committed.Builtins},
+	}
+
+	drifted := false
+	for _, section := range sections {
+		added := difference(section.generated, section.committed)
+		removed := difference(section.committed, section.generated)
+		if len(added) == 0 && len(removed) == 0 {
+			continue
+		}
+		if !drifted {
+			fmt.Fprintf(w, "%s is out of date:\n", source)
+			drifted = true
+		}
+		for _, name := range added {
+			fmt.Fprintf(w, "  added %s: %s\n", section.name, name)
+		}
+		for _, name := range removed {
+			fmt.Fprintf(w, "  removed %s: %s\n", section.name, name)
+		}
+	}
+	return drifted
+}
+
+// difference returns the entries of a that are missing in b, sorted.
+func difference(a, b []string) []string {
+	present := toSet(b)
+	var result []string
+	for _, entry := range a {
+		if !present[entry] {
+			result = append(result, entry)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+func toSet(entries []string) map[string]bool {
+	set := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		set[entry] = true
+	}
+	return set
+}
+
+// writeKotlin renders data as the complete GoStandardLibrary.kt source file.
+// Entries are emitted in a fixed order (keywords, builtins, then every stdlib
+// package followed by the short name it owns) so that the output only changes
+// when the toolchain does.
+func writeKotlin(w io.Writer, data GoDictionaryData) error {
+	var keys []string
+	comments := make(map[int]string)
+
+	comments[len(keys)] = "Keywords"
+	keys = append(keys, data.Keywords...)
+
+	builtinComments := map[string]string{
+		builtinType:  "Builtin types",
+		builtinConst: "Builtin constants",
+		builtinFunc:  "Builtin functions",
+	}
+	currentKind := ""
+	for _, builtin := range data.Builtins {
+		if kind := data.BuiltinKinds[builtin]; kind != currentKind {
+			comments[len(keys)] = builtinComments[kind]
+			currentKind = kind
+		}
+		keys = append(keys, builtin)
+	}
+
+	emitted := make(map[string]bool, len(data.Dictionary))
+	for _, key := range keys {
+		emitted[key] = true
+	}
+
+	currentGroup := ""
+	for _, pkg := range data.StdLibs {
+		group := strings.SplitN(pkg, "/", 2)[0]
+		if group != currentGroup {
+			comments[len(keys)] = "Standard library - " + group
+			currentGroup = group
+		}
+		for _, key := range []string{pkg, godict.ShortName(pkg)} {
+			if emitted[key] || !samePath(data.Dictionary[key], data.Dictionary[pkg]) {
+				continue
+			}
+			emitted[key] = true
+			keys = append(keys, key)
+		}
+	}
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintln(out, "package de.maibornwolff.dependacharta.pipeline.processing.dependencies.dictionaries")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "import de.maibornwolff.dependacharta.pipeline.analysis.model.Path")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "// Generated by tools/gen_go_dictionary.go -format kotlin. DO NOT EDIT.")
+	fmt.Fprintln(out, "class GoStandardLibrary : StandardLibrary {")
+	fmt.Fprintln(out, "    override fun get() = goDictionary")
+	fmt.Fprintln(out)
+	fmt.Fprintln(out, "    companion object {")
+	fmt.Fprintln(out, "        private val goDictionary = mapOf(")
+	for i, key := range keys {
+		if comment, ok := comments[i]; ok {
+			fmt.Fprintf(out, "            // %s\n", comment)
+		}
+		separator := ","
+		if i == len(keys)-1 {
+			separator = ""
+		}
+		fmt.Fprintf(out, "            %s to Path(listOf(%s))%s\n", strconv.Quote(key), kotlinStringList(data.Dictionary[key]), separator)
+	}
+	fmt.Fprintln(out, "        )")
+	fmt.Fprintln(out, "    }")
+	fmt.Fprintln(out, "}")
+	return out.Flush()
+}
+
+func kotlinStringList(parts []string) string {
+	quoted := make([]string, len(parts))
+	for i, part := range parts {
+		quoted[i] = strconv.Quote(part)
+	}
+	return strings.Join(quoted, ", ")
+}
+
+func samePath(a, b []string) bool {
+	return strings.Join(a, "/") == strings.Join(b, "/")
 }
@@ -0,0 +1,280 @@
+//go:build ignore
+
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/MaibornWolff/DependaCharta/tools/internal/godict"
+	"golang.org/x/mod/module"
+)
+
+// ModuleDictionaryData is the third-party counterpart of GoDictionaryData. It
+// describes the external modules required by one analyzed Go project.
+type ModuleDictionaryData struct {
+	GoVersion  string              `json:"goVersion"`
+	Modules    []ModuleInfo        `json:"modules"`
+	Dictionary map[string][]string `json:"dictionary"`
+	// ShortNames lists every package a short name can refer to. Dictionary
+	// keeps the first claimant in module and package order.
+	ShortNames map[string][]string `json:"shortNames"`
+	// Ambiguities is the subset of ShortNames claimed by more than one
+	// package. These must be resolved against the file's import paths.
+	Ambiguities map[string][]string `json:"ambiguities"`
+}
+
+// ModuleInfo describes one required module and the exported symbols of all of
+// its importable packages, keyed by import path.
+type ModuleInfo struct {
+	Path     string                    `json:"path"`
+	Version  string                    `json:"version"`
+	Sum      string                    `json:"sum,omitempty"`
+	Indirect bool                      `json:"indirect"`
+	Replace  string                    `json:"replace,omitempty"`
+	Source   string                    `json:"source"`
+	Packages map[string]godict.Symbols `json:"packages"`
+}
+
+const (
+	sourceModCache = "modcache"
+	sourceVendor   = "vendor"
+	sourceLocal    = "local"
+	sourceMissing  = "missing"
+)
+
+func main() {
+	project := flag.String("project", ".", "directory of the analyzed project containing go.mod and go.sum")
+	modCache := flag.String("modcache", defaultModCache(), "module cache to read module sources from")
+	directOnly := flag.Bool("direct", false, "only include modules that are not marked as // indirect")
+	flag.Parse()
+
+	data, err := buildModuleDictionary(*project, *modCache, *directOnly)
+	if err != nil {
+		log.Fatalf("Failed to build module dictionary: %v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(data); err != nil {
+		log.Fatalf("Failed to encode JSON: %v", err)
+	}
+
+	packages := 0
+	missing := 0
+	for _, module := range data.Modules {
+		packages += len(module.Packages)
+		if module.Source == sourceMissing {
+			missing++
+		}
+	}
+	fmt.Fprintf(os.Stderr, "Generated Go module dictionary with:\n")
+	fmt.Fprintf(os.Stderr, "- %d modules (%d not found locally)\n", len(data.Modules), missing)
+	fmt.Fprintf(os.Stderr, "- %d packages\n", packages)
+	fmt.Fprintf(os.Stderr, "- %d total dictionary entries\n", len(data.Dictionary))
+	fmt.Fprintf(os.Stderr, "- %d ambiguous short names\n", len(data.Ambiguities))
+}
+
+// defaultModCache mirrors the go command: GOMODCACHE, else GOPATH/pkg/mod,
+// else $HOME/go/pkg/mod. It does not run the go binary.
+func defaultModCache() string {
+	if modCache := os.Getenv("GOMODCACHE"); modCache != "" {
+		return modCache
+	}
+	gopath := os.Getenv("GOPATH")
+	if gopath == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		gopath = filepath.Join(home, "go")
+	}
+	return filepath.Join(filepath.SplitList(gopath)[0], "pkg", "mod")
+}
+
+func buildModuleDictionary(project, modCache string, directOnly bool) (ModuleDictionaryData, error) {
+	data := ModuleDictionaryData{
+		Modules:    []ModuleInfo{},
+		Dictionary: make(map[string][]string),
+	}
+
+	goMod, err := godict.ReadGoMod(filepath.Join(project, "go.mod"))
+	if err != nil {
+		return data, err
+	}
+	data.GoVersion = goMod.GoVersion
+
+	sums, err := readGoSum(filepath.Join(project, "go.sum"))
+	if err != nil {
+		return data, err
+	}
+
+	vendorDir := filepath.Join(project, "vendor")
+	useVendor := fileExists(filepath.Join(vendorDir, "modules.txt"))
+
+	modulePaths := make(map[string]bool, len(goMod.Requirements))
+	for _, req := range goMod.Requirements {
+		modulePaths[req.Path] = true
+	}
+
+	for _, req := range goMod.Requirements {
+		if directOnly && req.Indirect {
+			continue
+		}
+		// The sources and the go.sum entry are those of the replacement, if
+		// any. A directory replacement has no version and no go.sum entry.
+		sourcePath, sourceVersion := req.Path, req.Version
+		if req.Replace != nil {
+			sourcePath, sourceVersion = req.Replace.Path, req.Replace.Version
+		}
+		module := ModuleInfo{
+			Path:     req.Path,
+			Version:  req.Version,
+			Sum:      sums[sourcePath+" "+sourceVersion],
+			Indirect: req.Indirect,
+			Packages: make(map[string]godict.Symbols),
+		}
+
+		if req.Replace != nil {
+			module.Replace = req.Replace.String()
+		}
+		var dir string
+		switch {
+		case useVendor:
+			dir, module.Source = filepath.Join(vendorDir, filepath.FromSlash(req.Path)), sourceVendor
+		case req.Replace != nil && godict.IsLocal(*req.Replace):
+			dir, module.Source = req.Replace.Path, sourceLocal
+			if !filepath.IsAbs(dir) {
+				dir = filepath.Join(project, dir)
+			}
+		default:
+			module.Source = sourceModCache
+			if dir, err = moduleCacheDir(modCache, sourcePath, sourceVersion); err != nil {
+				return data, fmt.Errorf("%s@%s: %w", req.Path, req.Version, err)
+			}
+		}
+
+		if !fileExists(dir) {
+			fmt.Fprintf(os.Stderr, "Module %s@%s not found in %s\n", req.Path, req.Version, dir)
+			module.Source = sourceMissing
+		} else if err := collectModulePackages(dir, req.Path, modulePaths, module.Packages); err != nil {
+			return data, fmt.Errorf("%s@%s: %w", req.Path, req.Version, err)
+		}
+		data.Modules = append(data.Modules, module)
+	}
+
+	sort.Slice(data.Modules, func(i, j int) bool {
+		return data.Modules[i].Path < data.Modules[j].Path
+	})
+	var allPkgs []string
+	for _, module := range data.Modules {
+		pkgs := make([]string, 0, len(module.Packages))
+		for pkg := range module.Packages {
+			pkgs = append(pkgs, pkg)
+		}
+		sort.Strings(pkgs)
+		for _, pkg := range pkgs {
+			path := strings.Split(strings.ReplaceAll(pkg, "/", "."), ".")
+			data.Dictionary[pkg] = path
+			if name := godict.ShortName(pkg); data.Dictionary[name] == nil {
+				data.Dictionary[name] = path
+			}
+		}
+		allPkgs = append(allPkgs, pkgs...)
+	}
+	data.ShortNames, data.Ambiguities = godict.CollectShortNames(allPkgs)
+	return data, nil
+}
+
+// readGoSum returns the h1 hash of every module version in a go.sum file,
+// keyed by "path version". A missing go.sum yields an empty map.
+func readGoSum(path string) (map[string]string, error) {
+	sums := make(map[string]string)
+	file, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return sums, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 3 || strings.HasSuffix(fields[1], "/go.mod") {
+			continue
+		}
+		sums[fields[0]+" "+fields[1]] = fields[2]
+	}
+	return sums, scanner.Err()
+}
+
+// moduleCacheDir returns the extracted source directory of a module version,
+// using the case encoding of the module cache.
+func moduleCacheDir(modCache, path, version string) (string, error) {
+	escapedPath, err := module.EscapePath(path)
+	if err != nil {
+		return "", err
+	}
+	escapedVersion, err := module.EscapeVersion(version)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(modCache, filepath.FromSlash(escapedPath)+"@"+escapedVersion), nil
+}
+
+// collectModulePackages walks the module rooted at dir and adds the exported
+// symbols of every package that can be imported from outside the module.
+// Internal packages, testdata, nested modules and the directories of other
+// required modules (e.g. a /v2 major version inside vendor/) are skipped.
+func collectModulePackages(dir, modulePath string, modulePaths map[string]bool, packages map[string]godict.Symbols) error {
+	return filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !entry.IsDir() {
+			return nil
+		}
+
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		importPath := modulePath
+		if rel != "." {
+			importPath = modulePath + "/" + filepath.ToSlash(rel)
+			name := entry.Name()
+			if name == "testdata" || name == "internal" || name == "vendor" || strings.HasPrefix(name, ".") || strings.HasPrefix(name, "_") {
+				return filepath.SkipDir
+			}
+			if modulePaths[importPath] || fileExists(filepath.Join(path, "go.mod")) {
+				return filepath.SkipDir
+			}
+		}
+
+		docPkg, err := godict.ParsePackageDoc(path, importPath)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Skipping %s: %v\n", importPath, err)
+			return nil
+		}
+		if docPkg != nil {
+			packages[importPath] = godict.PackageSymbols(docPkg)
+		}
+		return nil
+	})
+}
+
+func fileExists(path string) bool {
+	_, err := os.Stat(path)
+	return err == nil
+}
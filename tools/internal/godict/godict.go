@@ -0,0 +1,128 @@
+// Package godict holds the parts of the Go dictionary generators
+// gen_go_dictionary.go and gen_go_module_dictionary.go that both of them
+// need: the exported symbols of a package, the short names packages are
+// referred to by, the platforms a stdlib package is available on and the
+// requirements of a go.mod file.
+package godict
+
+import (
+	"errors"
+	"go/ast"
+	"go/build"
+	"go/doc"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// Symbols lists the exported package-level identifiers of a package.
+// Constructors, constants and variables grouped under a type by go/doc are
+// listed with the package-level ones.
+type Symbols struct {
+	Types     []string `json:"types"`
+	Functions []string `json:"functions"`
+	Constants []string `json:"constants"`
+	Variables []string `json:"variables"`
+}
+
+var majorVersionPattern = regexp.MustCompile(`^v[0-9]+$`)
+
+// ParsePackageDoc returns the go/doc view of the package in dir, or nil if the
+// package has no Go files for the host platform.
+func ParsePackageDoc(dir, importPath string) (*doc.Package, error) {
+	buildPkg, err := build.Default.ImportDir(dir, 0)
+	if err != nil {
+		var noGoErr *build.NoGoError
+		if errors.As(err, &noGoErr) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	var files []*ast.File
+	for _, name := range buildPkg.GoFiles {
+		file, err := parser.ParseFile(fset, filepath.Join(dir, name), nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		files = append(files, file)
+	}
+
+	return doc.NewFromFiles(fset, files, importPath)
+}
+
+// PackageSymbols returns the sorted exported symbols of docPkg. A nil package
+// has no symbols.
+func PackageSymbols(docPkg *doc.Package) Symbols {
+	symbols := Symbols{Types: []string{}, Functions: []string{}, Constants: []string{}, Variables: []string{}}
+	if docPkg == nil {
+		return symbols
+	}
+
+	addValues := func(target *[]string, values []*doc.Value) {
+		for _, value := range values {
+			for _, name := range value.Names {
+				if token.IsExported(name) {
+					*target = append(*target, name)
+				}
+			}
+		}
+	}
+	addFuncs := func(funcs []*doc.Func) {
+		for _, function := range funcs {
+			symbols.Functions = append(symbols.Functions, function.Name)
+		}
+	}
+
+	addValues(&symbols.Constants, docPkg.Consts)
+	addValues(&symbols.Variables, docPkg.Vars)
+	addFuncs(docPkg.Funcs)
+	for _, docType := range docPkg.Types {
+		symbols.Types = append(symbols.Types, docType.Name)
+		addValues(&symbols.Constants, docType.Consts)
+		addValues(&symbols.Variables, docType.Vars)
+		addFuncs(docType.Funcs)
+	}
+
+	for _, list := range []*[]string{&symbols.Types, &symbols.Functions, &symbols.Constants, &symbols.Variables} {
+		sort.Strings(*list)
+	}
+	return symbols
+}
+
+// ShortName is the name a package is usually referred to by: the last path
+// element, except for major version suffixes such as math/rand/v2 or
+// github.com/jstemmer/go-junit-report/v2, which are imported as rand and
+// go-junit-report and not as v2.
+func ShortName(pkg string) string {
+	elements := strings.Split(pkg, "/")
+	name := elements[len(elements)-1]
+	if len(elements) > 1 && majorVersionPattern.MatchString(name) {
+		name = elements[len(elements)-2]
+	}
+	return name
+}
+
+// CollectShortNames maps each short name to all packages that claim it, and
+// returns the short names claimed by more than one package separately as
+// ambiguities. The packages of each short name are sorted.
+func CollectShortNames(pkgs []string) (shortNames, ambiguities map[string][]string) {
+	shortNames = make(map[string][]string)
+	for _, pkg := range pkgs {
+		name := ShortName(pkg)
+		shortNames[name] = append(shortNames[name], pkg)
+	}
+
+	ambiguities = make(map[string][]string)
+	for name, candidates := range shortNames {
+		sort.Strings(candidates)
+		if len(candidates) > 1 {
+			ambiguities[name] = candidates
+		}
+	}
+	return shortNames, ambiguities
+}
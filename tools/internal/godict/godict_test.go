@@ -0,0 +1,77 @@
+package godict
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestShortNameSkipsMajorVersionSuffixes(t *testing.T) {
+	for pkg, expected := range map[string]string{
+		"fmt":                                    "fmt",
+		"net/http":                               "http",
+		"math/rand/v2":                           "rand",
+		"encoding/json/v2":                       "json",
+		"github.com/jstemmer/go-junit-report/v2": "go-junit-report",
+		"v2":                                     "v2",
+	} {
+		if name := ShortName(pkg); name != expected {
+			t.Errorf("ShortName(%q) = %q, want %q", pkg, name, expected)
+		}
+	}
+}
+
+func TestCollectShortNamesRecordsAllCandidatesOfAmbiguousNames(t *testing.T) {
+	// given
+	pkgs := []string{"math/rand", "crypto/rand", "math/rand/v2", "net/http"}
+
+	// when
+	shortNames, ambiguities := CollectShortNames(pkgs)
+
+	// then
+	expectedShortNames := map[string][]string{
+		"rand": {"crypto/rand", "math/rand", "math/rand/v2"},
+		"http": {"net/http"},
+	}
+	if !reflect.DeepEqual(shortNames, expectedShortNames) {
+		t.Errorf("got %v\nwant %v", shortNames, expectedShortNames)
+	}
+	expectedAmbiguities := map[string][]string{"rand": {"crypto/rand", "math/rand", "math/rand/v2"}}
+	if !reflect.DeepEqual(ambiguities, expectedAmbiguities) {
+		t.Errorf("got %v\nwant %v", ambiguities, expectedAmbiguities)
+	}
+}
+
+func TestPackageSymbolsListsExportedPackageLevelIdentifiers(t *testing.T) {
+	// given
+	docPkg, err := ParsePackageDoc("testdata/shapes", "example.com/shapes")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// when
+	symbols := PackageSymbols(docPkg)
+
+	// then
+	expected := Symbols{
+		Types:     []string{"Circle", "Shape", "Size"},
+		Functions: []string{"NewCircle", "Register"},
+		Constants: []string{"Large", "Pi", "Small"},
+		Variables: []string{"Default"},
+	}
+	if !reflect.DeepEqual(symbols, expected) {
+		t.Errorf("got %+v\nwant %+v", symbols, expected)
+	}
+}
+
+func TestParsePackageDocReturnsNilForDirectoriesWithoutGoFiles(t *testing.T) {
+	// when
+	docPkg, err := ParsePackageDoc("testdata", "example.com")
+
+	// then
+	if err != nil || docPkg != nil {
+		t.Errorf("got %v, %v, want nil, nil", docPkg, err)
+	}
+	if symbols := PackageSymbols(docPkg); len(symbols.Types)+len(symbols.Functions)+len(symbols.Constants)+len(symbols.Variables) != 0 {
+		t.Errorf("got %+v for a nil package", symbols)
+	}
+}
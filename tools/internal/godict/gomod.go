@@ -0,0 +1,72 @@
+package godict
+
+import (
+	"os"
+
+	"golang.org/x/mod/modfile"
+	"golang.org/x/mod/module"
+)
+
+// GoMod is the part of a go.mod file the module dictionary needs.
+type GoMod struct {
+	GoVersion    string
+	Requirements []Requirement
+}
+
+// Requirement is a required module version.
+type Requirement struct {
+	Path     string
+	Version  string
+	Indirect bool
+	// Replace is the module the requirement is replaced by, or nil. Its
+	// Version is empty if it is replaced by a directory.
+	Replace *module.Version
+}
+
+// ReadGoMod parses the go.mod file at path. Like in the go command, a replace
+// directive with a version on its left side only applies to that version of
+// the module and takes precedence over one without a version.
+func ReadGoMod(path string) (GoMod, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return GoMod{}, err
+	}
+	file, err := modfile.Parse(path, data, nil)
+	if err != nil {
+		return GoMod{}, err
+	}
+
+	goMod := GoMod{Requirements: []Requirement{}}
+	if file.Go != nil {
+		goMod.GoVersion = file.Go.Version
+	}
+	for _, require := range file.Require {
+		goMod.Requirements = append(goMod.Requirements, Requirement{
+			Path:     require.Mod.Path,
+			Version:  require.Mod.Version,
+			Indirect: require.Indirect,
+			Replace:  replacement(file.Replace, require.Mod),
+		})
+	}
+	return goMod, nil
+}
+
+func replacement(replaces []*modfile.Replace, mod module.Version) *module.Version {
+	var unversioned *module.Version
+	for _, replace := range replaces {
+		switch {
+		case replace.Old.Path != mod.Path:
+		case replace.Old.Version == mod.Version:
+			return &replace.New
+		case replace.Old.Version == "":
+			unversioned = &replace.New
+		}
+	}
+	return unversioned
+}
+
+// IsLocal reports whether a replacement refers to a directory rather than to
+// a module version.
+func IsLocal(replace module.Version) bool {
+	return modfile.IsDirectoryPath(replace.Path)
+}
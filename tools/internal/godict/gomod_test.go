@@ -0,0 +1,89 @@
+package godict
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"golang.org/x/mod/module"
+)
+
+func writeGoMod(t *testing.T, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "go.mod")
+	if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestReadGoModAppliesReplacementsOnlyToMatchingVersions(t *testing.T) {
+	// given
+	path := writeGoMod(t, `module example.com/project
+
+go 1.24.0
+
+require (
+	github.com/google/uuid v1.6.0
+	github.com/gorilla/mux v1.8.1
+	github.com/lib/pq v1.10.9 // indirect
+)
+
+replace github.com/google/uuid v1.5.0 => ../uuid
+
+replace (
+	github.com/gorilla/mux => github.com/fork/mux v1.9.0
+	github.com/gorilla/mux v1.8.1 => ./mux
+)
+`)
+
+	// when
+	goMod, err := ReadGoMod(path)
+
+	// then
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := GoMod{
+		GoVersion: "1.24.0",
+		Requirements: []Requirement{
+			{Path: "github.com/google/uuid", Version: "v1.6.0"},
+			{Path: "github.com/gorilla/mux", Version: "v1.8.1", Replace: &module.Version{Path: "./mux"}},
+			{Path: "github.com/lib/pq", Version: "v1.10.9", Indirect: true},
+		},
+	}
+	if !reflect.DeepEqual(goMod, expected) {
+		t.Errorf("got %+v\nwant %+v", goMod, expected)
+	}
+}
+
+func TestReadGoModAppliesUnversionedReplacementsToAnyVersion(t *testing.T) {
+	// given
+	path := writeGoMod(t, "module example.com/project\n\nrequire github.com/gorilla/mux v1.8.1\n\nreplace github.com/gorilla/mux => github.com/fork/mux v1.9.0\n")
+
+	// when
+	goMod, err := ReadGoMod(path)
+
+	// then
+	if err != nil {
+		t.Fatal(err)
+	}
+	replace := goMod.Requirements[0].Replace
+	if replace == nil || *replace != (module.Version{Path: "github.com/fork/mux", Version: "v1.9.0"}) || IsLocal(*replace) {
+		t.Errorf("got replacement %v, want github.com/fork/mux@v1.9.0", replace)
+	}
+}
+
+func TestReadGoModFailsOnMalformedFiles(t *testing.T) {
+	// given
+	path := writeGoMod(t, "module example.com/project\n\nrequire github.com/gorilla/mux\n")
+
+	// when
+	_, err := ReadGoMod(path)
+
+	// then
+	if err == nil {
+		t.Error("expected an error for a require without version")
+	}
+}
@@ -0,0 +1,38 @@
+package godict
+
+import (
+	"errors"
+	"go/build"
+	"path/filepath"
+	"slices"
+)
+
+// stubFiles names the file that implements each stdlib package which has
+// files for every platform but only works on some of them. Elsewhere only
+// documentation or stubs returning errors are built: log/syslog keeps its
+// doc.go, plugin its plugin_stubs.go and runtime/cgo, which needs cgo, its
+// Go files without import "C".
+var stubFiles = map[string]string{
+	"log/syslog":  "syslog.go",
+	"plugin":      "plugin_dlopen.go",
+	"runtime/cgo": "cgo.go",
+}
+
+// Available reports whether the stdlib package pkg below context.GOROOT/src
+// builds for the platform of context, i.e. whether go/build selects Go files
+// for it. Packages in stubFiles also need their implementing file.
+func Available(context build.Context, pkg string) (bool, error) {
+	buildPkg, err := context.ImportDir(filepath.Join(context.GOROOT, "src", filepath.FromSlash(pkg)), 0)
+	if err != nil {
+		var noGoErr *build.NoGoError
+		if errors.As(err, &noGoErr) {
+			return false, nil
+		}
+		return false, err
+	}
+	built := append(append([]string(nil), buildPkg.GoFiles...), buildPkg.CgoFiles...)
+	if file, ok := stubFiles[pkg]; ok {
+		return slices.Contains(built, file), nil
+	}
+	return len(built) > 0, nil
+}
@@ -0,0 +1,65 @@
+package godict
+
+import (
+	"go/build"
+	"reflect"
+	"testing"
+)
+
+func TestAvailableFollowsTheBuiltFilesAndStubPackages(t *testing.T) {
+	// given
+	platforms := []struct {
+		goos, goarch string
+		cgo          bool
+	}{
+		{"linux", "amd64", true},
+		{"linux", "arm64", false},
+		{"windows", "amd64", true},
+		{"js", "wasm", false},
+	}
+	pkgs := []string{"archive/tar", "math/big", "log/syslog", "plugin", "runtime/cgo", "syscall/js"}
+
+	// when
+	availability := map[string][]string{}
+	for _, platform := range platforms {
+		context := build.Default
+		context.GOROOT = "testdata/goroot"
+		context.GOOS, context.GOARCH, context.CgoEnabled = platform.goos, platform.goarch, platform.cgo
+		for _, pkg := range pkgs {
+			available, err := Available(context, pkg)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if available {
+				availability[pkg] = append(availability[pkg], platform.goos+"/"+platform.goarch)
+			}
+		}
+	}
+
+	// then
+	expected := map[string][]string{
+		"archive/tar": {"linux/amd64", "linux/arm64", "windows/amd64", "js/wasm"},
+		"math/big":    {"linux/amd64", "linux/arm64", "windows/amd64", "js/wasm"},
+		"log/syslog":  {"linux/amd64", "linux/arm64", "js/wasm"},
+		"plugin":      {"linux/amd64"},
+		"runtime/cgo": {"linux/amd64", "windows/amd64"},
+		"syscall/js":  {"js/wasm"},
+	}
+	if !reflect.DeepEqual(availability, expected) {
+		t.Errorf("got %v\nwant %v", availability, expected)
+	}
+}
+
+func TestAvailableFailsForMissingPackage(t *testing.T) {
+	// given
+	context := build.Default
+	context.GOROOT = "testdata/goroot"
+
+	// when
+	_, err := Available(context, "net/http")
+
+	// then
+	if err == nil {
+		t.Error("expected an error")
+	}
+}
@@ -0,0 +1,2 @@
+// Package syslog provides an interface to the system log service.
+package syslog
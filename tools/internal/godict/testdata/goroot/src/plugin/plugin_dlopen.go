@@ -0,0 +1,5 @@
+//go:build (linux && cgo) || (darwin && cgo) || (freebsd && cgo)
+
+package plugin
+
+import "C"
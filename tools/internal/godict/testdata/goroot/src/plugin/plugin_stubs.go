@@ -0,0 +1,3 @@
+//go:build (!linux && !freebsd && !darwin) || !cgo
+
+package plugin
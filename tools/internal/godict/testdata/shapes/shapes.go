@@ -0,0 +1,28 @@
+// Package shapes is a fixture for the symbol extraction.
+package shapes
+
+const Pi = 3.14
+
+const (
+	Small Size = iota
+	Large
+	hidden
+)
+
+var Default = Circle{}
+
+var registry = map[string]Shape{}
+
+type Shape interface{ Area() float64 }
+
+type Size int
+
+type Circle struct{ Radius float64 }
+
+func NewCircle(radius float64) Circle { return Circle{radius} }
+
+func (c Circle) Area() float64 { return Pi * c.Radius * c.Radius }
+
+func Register(name string, shape Shape) { registry[name] = shape }
+
+func helper() {}